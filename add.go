@@ -0,0 +1,306 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/tj/go-naturaldate"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+// addOptions are the flags of 'clockidup add'.
+type addOptions struct {
+	day      time.Time     // The day of the entry, --at.
+	from, to string        // For example "9:30" or "yesterday at 2pm".
+	duration time.Duration // Zero when --duration isn't given.
+
+	// dayStart is when the working hours start on that day. With
+	// --duration alone, the entry starts there when the day has no entry.
+	dayStart time.Time
+}
+
+// parseAddSpec splits "project: task: description" into its parts. The task
+// and the project are optional, meaning that "project: description" and
+// "description" also work. To put a colon in the description of an entry
+// that has no task, leave the task empty, e.g. "project: : fix: typo".
+func parseAddSpec(spec string) (project, task, description string, _ error) {
+	parts := strings.SplitN(spec, ":", 3)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	switch len(parts) {
+	case 3:
+		project, task, description = parts[0], parts[1], parts[2]
+	case 2:
+		project, description = parts[0], parts[1]
+	default:
+		description = parts[0]
+	}
+	if description == "" {
+		return "", "", "", fmt.Errorf("the entry must be of the form 'project: task: description', got '%s'", spec)
+	}
+	return project, task, description, nil
+}
+
+// Go durations don't allow "1h30", which is how people write durations.
+var hoursAndMinutes = regexp.MustCompile(`^\d+h\d+$`)
+
+// parseHumanDuration parses a duration such as "1h30", "90m" or "1.5h".
+func parseHumanDuration(s string) (time.Duration, error) {
+	if hoursAndMinutes.MatchString(s) {
+		s += "m"
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("'%s' is not a duration, e.g. 1h30, 45m or 1.5h", s)
+	}
+	return d, nil
+}
+
+// parseTimeOn parses a time such as "9:30" or "2pm" on the given day. A day
+// can also be given, e.g. "yesterday at 9:30".
+func parseTimeOn(day time.Time, arg string) (time.Time, error) {
+	t, err := naturaldate.Parse(arg, startOfDay(day), naturaldate.WithDirection(naturaldate.Past))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is not a valid time, e.g. 9:30, 14:00 or 2pm", arg)
+	}
+	return t, nil
+}
+
+// matchNames returns the names that match the query. When a name is equal to
+// the query, ignoring case, it is the only match. Otherwise, the names that
+// contain the query are returned, and when there are none, the names that
+// are close to the query, e.g. "cert-manger" for "prod/cert-manager".
+func matchNames(names []string, query string) []string {
+	for _, name := range names {
+		if strings.EqualFold(name, query) {
+			return []string{name}
+		}
+	}
+
+	q := normalize(query)
+	var matches []string
+	for _, name := range names {
+		if strings.Contains(normalize(name), q) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	for _, name := range names {
+		n := normalize(name)
+		longest := len([]rune(n))
+		if l := len([]rune(q)); l > longest {
+			longest = l
+		}
+		if float64(levenshtein(n, q)) <= fuzzyThreshold*float64(longest) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// resolveName finds the project or task that the user meant. When several
+// names match, the user is asked to pick one.
+func resolveName(kind string, names []string, query string) (string, error) {
+	matches := matchNames(names, query)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s matches '%s', run 'clockidup %ss' to see the existing %ss", kind, query, kind, kind)
+	case 1:
+		return matches[0], nil
+	}
+
+	var picked string
+	err := survey.AskOne(&survey.Select{
+		Message: fmt.Sprintf("Several %ss match '%s':", kind, query),
+		Options: matches,
+	}, &picked)
+	if err != nil {
+		return "", err
+	}
+	return picked, nil
+}
+
+// entryInterval works out the start and end of the new entry. With
+// --duration alone, the entry ends now when it is added to today, and
+// otherwise starts when the last entry of the day ended.
+func entryInterval(opts addOptions, now time.Time, lastEnd func() (time.Time, error)) (start, end time.Time, err error) {
+	if opts.from != "" {
+		start, err = parseTimeOn(opts.day, opts.from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--from: %s", err)
+		}
+	}
+	if opts.to != "" {
+		end, err = parseTimeOn(opts.day, opts.to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--to: %s", err)
+		}
+	}
+	today := startOfDay(opts.day).Equal(startOfDay(now))
+
+	switch {
+	case opts.from != "" && opts.to != "" && opts.duration != 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("--duration can't be used along with both --from and --to")
+	case opts.from != "" && opts.to != "":
+	case opts.from != "" && opts.duration != 0:
+		end = start.Add(opts.duration)
+	case opts.to != "" && opts.duration != 0:
+		start = end.Add(-opts.duration)
+	case opts.from != "" && today:
+		end = now
+	case opts.duration != 0 && today:
+		end = now
+		start = end.Add(-opts.duration)
+	case opts.duration != 0:
+		start, err = lastEnd()
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = start.Add(opts.duration)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("the time of the entry is missing, e.g. '--from 9:30 --to 11:00' or '--duration 1h30'")
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the entry would end (%s) before it starts (%s)", end.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04"))
+	}
+	return start, end, nil
+}
+
+// addEntry creates a time entry in Clockify. The project and task names are
+// matched loosely, e.g. "cert-manager" is enough for "prod/cert-manager".
+// The entry is billable when the project is billable.
+func addEntry(client clockifyClient, workspaceName, spec string, opts addOptions, now time.Time) (timeEntry, error) {
+	projectQuery, taskQuery, description, err := parseAddSpec(spec)
+	if err != nil {
+		return timeEntry{}, err
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return timeEntry{}, err
+	}
+
+	start, end, err := entryInterval(opts, now, func() (time.Time, error) {
+		return lastEnd(client, workspace, opts, now)
+	})
+	if err != nil {
+		return timeEntry{}, err
+	}
+
+	endUTC := end.UTC()
+	create := clockify.CreateTimeEntryRequest{Start: start.UTC(), End: &endUTC, Description: description}
+	created := timeEntry{Description: description, Duration: end.Sub(start), Start: start, End: end}
+
+	if projectQuery != "" {
+		project, err := resolveProject(client, workspace.ID, projectQuery)
+		if err != nil {
+			return timeEntry{}, err
+		}
+		create.ProjectID, create.Billable = project.ID, project.Billable
+		created.Project, created.Billable = project.Name, project.Billable
+	}
+
+	if taskQuery != "" {
+		if create.ProjectID == "" {
+			return timeEntry{}, fmt.Errorf("the task '%s' needs a project, e.g. 'project: task: description'", taskQuery)
+		}
+		task, err := resolveTask(client, workspace.ID, create.ProjectID, taskQuery)
+		if err != nil {
+			return timeEntry{}, err
+		}
+		create.TaskID, created.Task = task.ID, task.Name
+	}
+
+	entry, err := client.CreateTimeEntry(workspace.ID, create)
+	if err != nil {
+		return timeEntry{}, fmt.Errorf("while creating the time entry: %s", err)
+	}
+	created.IDs = []string{entry.ID}
+
+	return created, nil
+}
+
+// lastEnd returns when the last entry of the day ended, which is where an
+// entry added with --duration alone starts. A timer still running ends now.
+// On a day with no entry, the working hours start is returned instead.
+func lastEnd(client clockifyClient, workspace clockify.Workspace, opts addOptions, now time.Time) (time.Time, error) {
+	entries, err := client.TimeEntries(workspace.ID, workspace.Memberships[0].UserID, startOfDay(opts.day), endOfDay(opts.day))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("while fetching time entries: %s", err)
+	}
+	var last time.Time
+	for _, entry := range entries {
+		end := entry.TimeInterval.End
+		if end.IsZero() {
+			end = now
+		}
+		if end.After(last) {
+			last = end
+		}
+	}
+	if last.IsZero() {
+		return opts.dayStart, nil
+	}
+	return last, nil
+}
+
+func runAdd(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	atFlag := flags.String("at", "today", "The day of the entry.")
+	fromFlag := flags.String("from", "", "When the entry started, e.g. 9:30 or 2pm.")
+	toFlag := flags.String("to", "", "When the entry ended, e.g. 11:00.")
+	durationFlag := flags.String("duration", "", "How long the entry lasted, e.g. 1h30 or 45m.")
+
+	// The flags can come after the entry, e.g. 'clockidup add "admin:
+	// expenses" --from 9:30', which the flag package doesn't allow.
+	var positional []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected a single entry, e.g. 'clockidup add \"prod/cert-manager: reviewing PR 3574\" --from 9:30 --to 11:00', got %d arguments", len(positional))
+	}
+
+	day, err := parseDay(*atFlag)
+	if err != nil {
+		return err
+	}
+	dayStart, _, err := workingHours(conf, day)
+	if err != nil {
+		return err
+	}
+	opts := addOptions{day: day, from: *fromFlag, to: *toFlag, dayStart: dayStart}
+	if *durationFlag != "" {
+		opts.duration, err = parseHumanDuration(*durationFlag)
+		if err != nil {
+			return fmt.Errorf("--duration: %s", err)
+		}
+	}
+
+	entry, err := addEntry(client, workspaceName, positional[0], opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	line := standupLines([]timeEntry{entry}, lineOptions{Start: true, End: true})[0]
+	fmt.Printf("Added %s %s\n", entry.Start.Local().Format(layoutISO), line)
+	return nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+func Test_parseAddSpec(t *testing.T) {
+	tests := []struct {
+		given           string
+		wantProject     string
+		wantTask        string
+		wantDescription string
+		wantErr         string
+	}{
+		{given: "prod/cert-manager: 1.2: reviewing PR 3574", wantProject: "prod/cert-manager", wantTask: "1.2", wantDescription: "reviewing PR 3574"},
+		{given: "admin: expenses", wantProject: "admin", wantDescription: "expenses"},
+		{given: "expenses", wantDescription: "expenses"},
+		{given: "prod/cert-manager: : fix: typo", wantProject: "prod/cert-manager", wantDescription: "fix: typo"},
+		{given: "admin:", wantErr: "the entry must be of the form 'project: task: description', got 'admin:'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			project, task, description, err := parseAddSpec(tt.given)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProject, project)
+			assert.Equal(t, tt.wantTask, task)
+			assert.Equal(t, tt.wantDescription, description)
+		})
+	}
+}
+
+func Test_parseHumanDuration(t *testing.T) {
+	tests := []struct {
+		given   string
+		want    time.Duration
+		wantErr string
+	}{
+		{given: "1h30", want: 90 * time.Minute},
+		{given: "45m", want: 45 * time.Minute},
+		{given: "1.5h", want: 90 * time.Minute},
+		{given: "-1h", wantErr: "'-1h' is not a duration, e.g. 1h30, 45m or 1.5h"},
+		{given: "an hour", wantErr: "'an hour' is not a duration, e.g. 1h30, 45m or 1.5h"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := parseHumanDuration(tt.given)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchNames(t *testing.T) {
+	names := []string{"prod/cert-manager", "prod/cert-manager-csi", "admin", "Admin/hiring"}
+	tests := []struct {
+		given string
+		want  []string
+	}{
+		{given: "ADMIN", want: []string{"admin"}},
+		{given: "cert-manager", want: []string{"prod/cert-manager", "prod/cert-manager-csi"}},
+		{given: "csi", want: []string{"prod/cert-manager-csi"}},
+		{given: "prod/cert-manger", want: []string{"prod/cert-manager"}},
+		{given: "website", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchNames(names, tt.given))
+		})
+	}
+}
+
+func Test_entryInterval(t *testing.T) {
+	now := mustParse("2021-07-05T15:00:00Z")
+	today, yesterday := mustParse("2021-07-05T00:00:00Z"), mustParse("2021-07-04T00:00:00Z")
+	lastEnd := func() (time.Time, error) { return mustParse("2021-07-04T17:00:00Z"), nil }
+	noEntry := func() (time.Time, error) {
+		return time.Time{}, fmt.Errorf("no entry on 2021-07-04 to put the new entry after, use --from or --to")
+	}
+
+	tests := []struct {
+		name      string
+		given     addOptions
+		lastEnd   func() (time.Time, error)
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   string
+	}{
+		{
+			name:      "--from and --to",
+			given:     addOptions{day: yesterday, from: "9:30", to: "11:00"},
+			wantStart: mustParse("2021-07-04T09:30:00Z"), wantEnd: mustParse("2021-07-04T11:00:00Z"),
+		},
+		{
+			name:      "--from and --duration",
+			given:     addOptions{day: today, from: "2pm", duration: 30 * time.Minute},
+			wantStart: mustParse("2021-07-05T14:00:00Z"), wantEnd: mustParse("2021-07-05T14:30:00Z"),
+		},
+		{
+			name:      "--to and --duration",
+			given:     addOptions{day: today, to: "11:00", duration: 90 * time.Minute},
+			wantStart: mustParse("2021-07-05T09:30:00Z"), wantEnd: mustParse("2021-07-05T11:00:00Z"),
+		},
+		{
+			name:      "--from with a day",
+			given:     addOptions{day: today, from: "yesterday at 9:30", to: "yesterday at 10:00"},
+			wantStart: mustParse("2021-07-04T09:30:00Z"), wantEnd: mustParse("2021-07-04T10:00:00Z"),
+		},
+		{
+			name:      "--from alone today ends now",
+			given:     addOptions{day: today, from: "14:00"},
+			wantStart: mustParse("2021-07-05T14:00:00Z"), wantEnd: now,
+		},
+		{
+			name:      "--duration alone today ends now",
+			given:     addOptions{day: today, duration: time.Hour},
+			wantStart: mustParse("2021-07-05T14:00:00Z"), wantEnd: now,
+		},
+		{
+			name:      "--duration alone on another day starts after the last entry",
+			given:     addOptions{day: yesterday, duration: 90 * time.Minute},
+			lastEnd:   lastEnd,
+			wantStart: mustParse("2021-07-04T17:00:00Z"), wantEnd: mustParse("2021-07-04T18:30:00Z"),
+		},
+		{
+			name:    "--duration alone on a day without entries",
+			given:   addOptions{day: yesterday, duration: 90 * time.Minute},
+			lastEnd: noEntry,
+			wantErr: "no entry on 2021-07-04 to put the new entry after, use --from or --to",
+		},
+		{
+			name:    "--from alone on another day",
+			given:   addOptions{day: yesterday, from: "9:30"},
+			wantErr: "the time of the entry is missing, e.g. '--from 9:30 --to 11:00' or '--duration 1h30'",
+		},
+		{
+			name:    "--from, --to and --duration",
+			given:   addOptions{day: today, from: "9:30", to: "11:00", duration: time.Hour},
+			wantErr: "--duration can't be used along with both --from and --to",
+		},
+		{
+			name:    "--to before --from",
+			given:   addOptions{day: today, from: "11:00", to: "9:30"},
+			wantErr: "the entry would end (2021-07-05 09:30) before it starts (2021-07-05 11:00)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.lastEnd == nil {
+				tt.lastEnd = func() (time.Time, error) {
+					t.Error("lastEnd wasn't expected to be called")
+					return time.Time{}, nil
+				}
+			}
+			start, end, err := entryInterval(tt.given, now, tt.lastEnd)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func Test_entryInterval_isoDay(t *testing.T) {
+	// The day given as 2021-07-05 must be a local day, like "today", so
+	// that --from 9:30 means 9:30 local time.
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	local := time.Local
+	time.Local = tokyo
+	defer func() { time.Local = local }()
+
+	day, err := parseDay("2021-07-05")
+	require.NoError(t, err)
+
+	start, end, err := entryInterval(addOptions{day: day, from: "9:30", to: "11:00"}, mustParse("2021-07-06T15:00:00Z"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, mustParse("2021-07-05T00:30:00Z"), start.UTC())
+	assert.Equal(t, mustParse("2021-07-05T02:00:00Z"), end.UTC())
+}
+
+func Test_lastEnd(t *testing.T) {
+	now := mustParse("2021-07-05T15:00:00Z")
+	day := mustParse("2021-07-04T00:00:00Z")
+	dayStart := mustParse("2021-07-04T09:00:00Z")
+	workspace := clockify.Workspace{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}
+	entry := func(start, end string) clockify.TimeEntry {
+		var e clockify.TimeEntry
+		e.TimeInterval.Start = mustParse(start)
+		if end != "" {
+			e.TimeInterval.End = mustParse(end)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name  string
+		given []clockify.TimeEntry
+		want  time.Time
+	}{
+		{
+			name:  "the last entry ended at 11:00",
+			given: []clockify.TimeEntry{entry("2021-07-04T10:00:00Z", "2021-07-04T11:00:00Z"), entry("2021-07-04T09:00:00Z", "2021-07-04T10:00:00Z")},
+			want:  mustParse("2021-07-04T11:00:00Z"),
+		},
+		{
+			name:  "a timer is still running",
+			given: []clockify.TimeEntry{entry("2021-07-04T10:00:00Z", ""), entry("2021-07-04T09:00:00Z", "2021-07-04T10:00:00Z")},
+			want:  now,
+		},
+		{
+			name: "no entry on that day",
+			want: dayStart,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mocks.NewMockclockifyClient(ctrl)
+			client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", startOfDay(day), endOfDay(day)).Return(tt.given, nil)
+
+			got, err := lastEnd(client, workspace, addOptions{day: day, duration: time.Hour, dayStart: dayStart}, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_addEntry(t *testing.T) {
+	now := mustParse("2021-07-05T15:00:00Z")
+	workspaces := []clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}
+	projects := []clockify.Project{
+		{ID: "project-1-uid", Name: "prod/cert-manager", Billable: true},
+		{ID: "project-2-uid", Name: "admin"},
+		{ID: "project-3-uid", Name: "prod/cert-manager-old", Archived: true},
+	}
+	start, end := mustParse("2021-07-04T09:30:00Z"), mustParse("2021-07-04T11:00:00Z")
+
+	tests := []struct {
+		name      string
+		givenSpec string
+		givenMock func(m *mocks.MockclockifyClientMockRecorder)
+		want      timeEntry
+		wantErr   string
+	}{
+		{
+			name:      "project and task are resolved",
+			givenSpec: "cert-manager: 1.2: reviewing PR 3574",
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+				m.Tasks("workspace-1-uid", "project-1-uid").Return([]clockify.Task{{ID: "task-1-uid", Name: "1.2"}, {ID: "task-2-uid", Name: "1.3"}}, nil)
+				m.CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{
+					Start: start, End: &end, Billable: true, Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid",
+				}).Return(clockify.TimeEntry{ID: "entry-1-uid"}, nil)
+			},
+			want: timeEntry{IDs: []string{"entry-1-uid"}, Project: "prod/cert-manager", Task: "1.2", Description: "reviewing PR 3574", Billable: true, Duration: 90 * time.Minute, Start: start, End: end},
+		},
+		{
+			name:      "no project",
+			givenSpec: "reading the news",
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{
+					Start: start, End: &end, Description: "reading the news",
+				}).Return(clockify.TimeEntry{ID: "entry-1-uid"}, nil)
+			},
+			want: timeEntry{IDs: []string{"entry-1-uid"}, Description: "reading the news", Duration: 90 * time.Minute, Start: start, End: end},
+		},
+		{
+			name:      "unknown project",
+			givenSpec: "website: redesign",
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+			},
+			wantErr: "no project matches 'website', run 'clockidup projects' to see the existing projects",
+		},
+		{
+			name:      "Clockify refuses the entry",
+			givenSpec: "admin: expenses",
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+				m.CreateTimeEntry("workspace-1-uid", gomock.Any()).Return(clockify.TimeEntry{}, fmt.Errorf("400 Bad Request: Time entry is locked"))
+			},
+			wantErr: "while creating the time entry: 400 Bad Request: Time entry is locked",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mocks.NewMockclockifyClient(ctrl)
+			tt.givenMock(client.EXPECT())
+
+			got, err := addEntry(client, "workspace-1", tt.givenSpec, addOptions{day: mustParse("2021-07-04T00:00:00Z"), from: "9:30", to: "11:00"}, now)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// targetForDay returns how many hours the user is expected to log on the
+// given day. When the config has no 'target-hours', hasTarget is false.
+// Weekdays that are not listed in 'target-hours' as well as holidays have a
+// target of zero.
+func targetForDay(conf Config, day time.Time) (_ time.Duration, hasTarget bool, _ error) {
+	if len(conf.TargetHours) == 0 {
+		return 0, false, nil
+	}
+
+	for weekday := range conf.TargetHours {
+		if !isWeekday(weekday) {
+			return 0, false, fmt.Errorf("target-hours: '%s' is not a weekday, it should be one of monday, tuesday, wednesday, thursday, friday, saturday, sunday", weekday)
+		}
+	}
+
+	for _, holiday := range conf.Holidays {
+		if _, err := time.Parse(layoutISO, holiday); err != nil {
+			return 0, false, fmt.Errorf("holidays: '%s' is not of the form 2021-12-31", holiday)
+		}
+		if holiday == day.Format(layoutISO) {
+			return 0, true, nil
+		}
+	}
+
+	hours := conf.TargetHours[strings.ToLower(day.Weekday().String())]
+	return time.Duration(hours * float64(time.Hour)), true, nil
+}
+
+func isWeekday(s string) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func totalDuration(entries []timeEntry) time.Duration {
+	var total time.Duration
+	for _, entry := range entries {
+		total += entry.Duration
+	}
+	return total
+}
+
+// formatLogged returns a string of the form "logged 6.2h / 7.5h (-1.3h)".
+func formatLogged(logged, target time.Duration) string {
+	return fmt.Sprintf("logged %.1fh / %.1fh (%s)", logged.Hours(), target.Hours(), formatDiff(logged-target))
+}
+
+// formatDiff shows the difference with an explicit sign, e.g. "+0.3h" or
+// "-1.3h". A difference that rounds to zero is shown as "+0.0h" instead of
+// "-0.0h".
+func formatDiff(diff time.Duration) string {
+	hours := math.Round(diff.Hours()*10) / 10
+	if hours == 0 {
+		hours = 0
+	}
+	return fmt.Sprintf("%+.1fh", hours)
+}
+
+// dayBalance is what has been logged on a given day compared to the target.
+type dayBalance struct {
+	Day     time.Time
+	Logged  time.Duration
+	Target  time.Duration
+	Holiday bool
+}
+
+// computeBalance buckets the given time entries per day, using the location
+// of 'since' to decide which day an entry belongs to, and compares each day
+// to its target. Both since and until are included.
+func computeBalance(conf Config, entries []timeEntry, since, until time.Time) ([]dayBalance, error) {
+	loc := since.Location()
+
+	logged := make(map[string]time.Duration)
+	for _, entry := range entries {
+		logged[entry.Start.In(loc).Format(layoutISO)] += entry.Duration
+	}
+
+	holidays := make(map[string]bool)
+	for _, holiday := range conf.Holidays {
+		holidays[holiday] = true
+	}
+
+	var days []dayBalance
+	for day := startOfDay(since); !day.After(until); day = day.AddDate(0, 0, 1) {
+		target, _, err := targetForDay(conf, day)
+		if err != nil {
+			return nil, err
+		}
+
+		days = append(days, dayBalance{
+			Day:     day,
+			Logged:  logged[day.Format(layoutISO)],
+			Target:  target,
+			Holiday: holidays[day.Format(layoutISO)],
+		})
+	}
+
+	return days, nil
+}
+
+func startOfDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+}
+
+func endOfDay(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, day.Location())
+}
+
+func runBalance(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("balance", flag.ContinueOnError)
+	sinceFlag := flags.String("since", "", "First day to be taken into account, e.g. 2021-01-01.")
+	untilFlag := flags.String("until", "today", "Last day to be taken into account.")
+	perDay := flags.Bool("per-day", false, "Also show the balance of each day.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if len(conf.TargetHours) == 0 {
+		return fmt.Errorf("no target hours configured, add 'target-hours' to ~/%s", confPath)
+	}
+	if *sinceFlag == "" {
+		return fmt.Errorf("--since is required, e.g. 'clockidup balance --since 2021-01-01'")
+	}
+	since, err := parseDay(*sinceFlag)
+	if err != nil {
+		return err
+	}
+	until, err := parseDay(*untilFlag)
+	if err != nil {
+		return err
+	}
+	if until.Before(startOfDay(since)) {
+		return fmt.Errorf("--until (%s) is before --since (%s)", until.Format(layoutISO), since.Format(layoutISO))
+	}
+
+	entries, err := timeEntriesForRange(client, time.Now, workspaceName, startOfDay(since), endOfDay(until))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	days, err := computeBalance(conf, entries, since, endOfDay(until))
+	if err != nil {
+		return err
+	}
+
+	var logged, target time.Duration
+	for _, day := range days {
+		logged += day.Logged
+		target += day.Target
+
+		if !*perDay || (day.Logged == 0 && day.Target == 0) {
+			continue
+		}
+		holiday := ""
+		if day.Holiday {
+			holiday = " (holiday)"
+		}
+		fmt.Printf("%s %s: %s%s\n", day.Day.Format(layoutISO), day.Day.Format("Mon"), formatLogged(day.Logged, day.Target), holiday)
+	}
+
+	fmt.Printf("%s\n", formatLogged(logged, target))
+	return nil
+}
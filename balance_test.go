@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_targetForDay(t *testing.T) {
+	tests := []struct {
+		name          string
+		givenConf     Config
+		givenDay      time.Time
+		want          time.Duration
+		wantHasTarget bool
+		wantErr       string
+	}{
+		{
+			name:          "no target hours configured",
+			givenConf:     Config{},
+			givenDay:      mustParse("2021-07-05T00:00:00Z"),
+			wantHasTarget: false,
+		},
+		{
+			name:          "monday has a target",
+			givenConf:     Config{TargetHours: map[string]float64{"monday": 7.5}},
+			givenDay:      mustParse("2021-07-05T00:00:00Z"),
+			want:          7*time.Hour + 30*time.Minute,
+			wantHasTarget: true,
+		},
+		{
+			name:          "weekday not listed has a zero target",
+			givenConf:     Config{TargetHours: map[string]float64{"monday": 7.5}},
+			givenDay:      mustParse("2021-07-03T00:00:00Z"), // Saturday
+			want:          0,
+			wantHasTarget: true,
+		},
+		{
+			name:          "holidays have a zero target",
+			givenConf:     Config{TargetHours: map[string]float64{"monday": 7.5}, Holidays: []string{"2021-07-05"}},
+			givenDay:      mustParse("2021-07-05T00:00:00Z"),
+			want:          0,
+			wantHasTarget: true,
+		},
+		{
+			name:      "unknown weekday",
+			givenConf: Config{TargetHours: map[string]float64{"mon": 7.5}},
+			givenDay:  mustParse("2021-07-05T00:00:00Z"),
+			wantErr:   "target-hours: 'mon' is not a weekday, it should be one of monday, tuesday, wednesday, thursday, friday, saturday, sunday",
+		},
+		{
+			name:      "invalid holiday",
+			givenConf: Config{TargetHours: map[string]float64{"monday": 7.5}, Holidays: []string{"05/07/2021"}},
+			givenDay:  mustParse("2021-07-05T00:00:00Z"),
+			wantErr:   "holidays: '05/07/2021' is not of the form 2021-12-31",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotHasTarget, err := targetForDay(tt.givenConf, tt.givenDay)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantHasTarget, gotHasTarget)
+		})
+	}
+}
+
+func Test_formatLogged(t *testing.T) {
+	tests := []struct {
+		givenLogged time.Duration
+		givenTarget time.Duration
+		want        string
+	}{
+		{givenLogged: 372 * time.Minute, givenTarget: 450 * time.Minute, want: "logged 6.2h / 7.5h (-1.3h)"},
+		{givenLogged: 468 * time.Minute, givenTarget: 450 * time.Minute, want: "logged 7.8h / 7.5h (+0.3h)"},
+		{givenLogged: 449 * time.Minute, givenTarget: 450 * time.Minute, want: "logged 7.5h / 7.5h (+0.0h)"},
+		{givenLogged: 60 * time.Minute, givenTarget: 0, want: "logged 1.0h / 0.0h (+1.0h)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatLogged(tt.givenLogged, tt.givenTarget))
+		})
+	}
+}
+
+func Test_computeBalance(t *testing.T) {
+	conf := Config{
+		TargetHours: map[string]float64{"friday": 7.5, "monday": 7.5},
+		Holidays:    []string{"2021-07-05"},
+	}
+	entries := []timeEntry{
+		{Description: "friday afternoon", Duration: 3 * time.Hour, Start: mustParse("2021-07-02T14:00:00Z")},
+		{Description: "friday morning", Duration: 5 * time.Hour, Start: mustParse("2021-07-02T08:00:00Z")},
+		{Description: "saturday", Duration: 1 * time.Hour, Start: mustParse("2021-07-03T10:00:00Z")},
+	}
+
+	got, err := computeBalance(conf, entries, mustParse("2021-07-02T00:00:00Z"), mustParse("2021-07-05T23:59:59Z"))
+	require.NoError(t, err)
+	assert.Equal(t, []dayBalance{
+		{Day: mustParse("2021-07-02T00:00:00Z"), Logged: 8 * time.Hour, Target: 7*time.Hour + 30*time.Minute},
+		{Day: mustParse("2021-07-03T00:00:00Z"), Logged: 1 * time.Hour, Target: 0},
+		{Day: mustParse("2021-07-04T00:00:00Z"), Logged: 0, Target: 0},
+		{Day: mustParse("2021-07-05T00:00:00Z"), Logged: 0, Target: 0, Holiday: true},
+	}, got)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// The bar of a project that used all of its estimate is this wide.
+const budgetBarWidth = 30
+
+// The standup warns about the projects that used at least 80% and then 100%
+// of their estimate.
+var defaultBudgetThresholds = []float64{80, 100}
+
+// isoDuration matches the ISO 8601 durations used by Clockify, e.g.
+// "PT1H30M" or "P2DT4H".
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration parses the ISO 8601 durations used by Clockify. A day is
+// 24 hours. The years, months and weeks are not supported since Clockify
+// doesn't use them.
+func parseISODuration(s string) (time.Duration, error) {
+	m := isoDuration.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("'%s' is not an ISO 8601 duration of the form PT1H30M", s)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute} {
+		if m[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not an ISO 8601 duration: %s", s, err)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[4] != "" {
+		secs, err := strconv.ParseFloat(m[4], 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not an ISO 8601 duration: %s", s, err)
+		}
+		d += time.Duration(secs * float64(time.Second))
+	}
+	return d, nil
+}
+
+// budgetUsage is how much of its time estimate or of its budget a project
+// used.
+type budgetUsage struct {
+	Project string
+	Kind    string // Either "estimate" (time) or "budget" (money).
+	Used    string // For example "34h" or "1200.00 USD".
+	Limit   string // For example "40h" or "1000.00 USD".
+	Ratio   float64
+}
+
+// computeBudgets returns the usage of the projects that have an active time
+// estimate or budget. The time used is the time tracked on the project by
+// everyone, and the budget used is that time at the project's rate. The
+// estimates that reset every month are left out since Clockify only gives
+// the time tracked since the start of the project. The projects closest to
+// their estimate come first.
+func computeBudgets(workspace clockify.Workspace, projects []clockify.Project) ([]budgetUsage, error) {
+	var usages []budgetUsage
+	for _, p := range projects {
+		est, b := p.TimeEstimate, p.BudgetEstimate
+		hasEstimate := est.Active && est.ResetOption == ""
+		hasBudget := b != nil && b.Active && b.ResetOption == "" && b.Estimate > 0
+		if p.Archived || (!hasEstimate && !hasBudget) {
+			continue
+		}
+
+		tracked, err := parseISODuration(p.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("project '%s': duration: %s", p.Name, err)
+		}
+
+		if hasEstimate {
+			estimate, err := parseISODuration(est.Estimate)
+			if err != nil {
+				return nil, fmt.Errorf("project '%s': time estimate: %s", p.Name, err)
+			}
+			if estimate > 0 {
+				usages = append(usages, budgetUsage{
+					Project: p.Name,
+					Kind:    "estimate",
+					Used:    formatDuration(tracked),
+					Limit:   formatDuration(estimate),
+					Ratio:   float64(tracked) / float64(estimate),
+				})
+			}
+		}
+
+		if hasBudget {
+			p := p
+			rate := hourlyRate(workspace, &p, "")
+			spent := earned(tracked, rate)
+			usages = append(usages, budgetUsage{
+				Project: p.Name,
+				Kind:    "budget",
+				Used:    formatMoney(spent, rate.Currency),
+				Limit:   formatMoney(b.Estimate, rate.Currency),
+				Ratio:   float64(spent) / float64(b.Estimate),
+			})
+		}
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].Ratio != usages[j].Ratio {
+			return usages[i].Ratio > usages[j].Ratio
+		}
+		return usages[i].Project < usages[j].Project
+	})
+	return usages, nil
+}
+
+// progressBar returns e.g. "██████████████████████████░░░░  85%". The bar
+// stops at 100%.
+func progressBar(ratio float64) string {
+	filled := int(math.Round(math.Min(ratio, 1) * budgetBarWidth))
+	return strings.Repeat("█", filled) + strings.Repeat("░", budgetBarWidth-filled) + fmt.Sprintf(" %3.0f%%", 100*ratio)
+}
+
+// budgetWarnings returns a warning for each of the given projects that
+// crossed one of the thresholds (in percent), e.g.:
+//
+//  warning: prod/cert-manager used 85% of its estimate (34h of 40h)
+//  warning: website is over its budget (1200.00 USD of 1000.00 USD, 120%)
+func budgetWarnings(usages []budgetUsage, thresholds []float64, projects map[string]bool) []string {
+	var warnings []string
+	for _, u := range usages {
+		if !projects[u.Project] {
+			continue
+		}
+		crossed := false
+		for _, t := range thresholds {
+			if 100*u.Ratio >= t {
+				crossed = true
+			}
+		}
+		switch {
+		case crossed && u.Ratio >= 1:
+			warnings = append(warnings, fmt.Sprintf("warning: %s is over its %s (%s of %s, %.0f%%)", u.Project, u.Kind, u.Used, u.Limit, 100*u.Ratio))
+		case crossed:
+			warnings = append(warnings, fmt.Sprintf("warning: %s used %.0f%% of its %s (%s of %s)", u.Project, 100*u.Ratio, u.Kind, u.Used, u.Limit))
+		}
+	}
+	return warnings
+}
+
+// budgetThresholds returns the thresholds from the config. No threshold
+// means that the standup doesn't show warnings.
+func budgetThresholds(conf Budgets) []float64 {
+	if conf.Disabled {
+		return nil
+	}
+	if len(conf.Thresholds) == 0 {
+		return defaultBudgetThresholds
+	}
+	return conf.Thresholds
+}
+
+// standupWarnings returns the warnings about the projects of the entries.
+// The entries are the ones from Clockify, before they are rewritten. The
+// hidden projects are left out and the others are shown under their alias.
+// Since the warnings are only a hint, a project whose estimate can't be read
+// is logged rather than failing the standup.
+func standupWarnings(conf Config, rw rewriter, workspace clockify.Workspace, projects []clockify.Project, entries []timeEntry) []string {
+	thresholds := budgetThresholds(conf.Budgets)
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	usages, err := computeBudgets(workspace, projects)
+	if err != nil {
+		logutil.Errorf("the budget warnings are left out: %s", err)
+		return nil
+	}
+	worked := make(map[string]bool)
+	for _, entry := range entries {
+		if !rw.hidden[entry.Project] {
+			worked[entry.Project] = true
+		}
+	}
+
+	var shown []budgetUsage
+	shownNames := make(map[string]bool)
+	for _, u := range usages {
+		if !worked[u.Project] {
+			continue
+		}
+		if alias, ok := rw.aliases[u.Project]; ok {
+			u.Project = alias
+		}
+		shown = append(shown, u)
+		shownNames[u.Project] = true
+	}
+	return budgetWarnings(shown, thresholds, shownNames)
+}
+
+func runBudgets(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("budgets", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+	projects, err := client.Projects(workspace.ID)
+	if err != nil {
+		return fmt.Errorf("while fetching projects: %s", err)
+	}
+
+	usages, err := computeBudgets(workspace, projects)
+	if err != nil {
+		return err
+	}
+	if len(usages) == 0 {
+		fmt.Println("No project has a time estimate or a budget.")
+		return nil
+	}
+
+	var rows [][]string
+	for _, u := range usages {
+		rows = append(rows, []string{u.Project, u.Used, u.Limit, progressBar(u.Ratio)})
+	}
+	return printTable(os.Stdout, []string{"PROJECT", "USED", "ESTIMATE", "PROGRESS"}, rows)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+func Test_parseISODuration(t *testing.T) {
+	tests := []struct {
+		given   string
+		want    time.Duration
+		wantErr string
+	}{
+		{given: "PT0S", want: 0},
+		{given: "PT40H", want: 40 * time.Hour},
+		{given: "PT1H30M", want: 90 * time.Minute},
+		{given: "PT45M12.5S", want: 45*time.Minute + 12500*time.Millisecond},
+		{given: "P2DT4H", want: 52 * time.Hour},
+		{given: "P1D", want: 24 * time.Hour},
+		{given: "", wantErr: "'' is not an ISO 8601 duration of the form PT1H30M"},
+		{given: "P", wantErr: "'P' is not an ISO 8601 duration of the form PT1H30M"},
+		{given: "PT", wantErr: "'PT' is not an ISO 8601 duration of the form PT1H30M"},
+		{given: "1h30m", wantErr: "'1h30m' is not an ISO 8601 duration of the form PT1H30M"},
+		{given: "P1W", wantErr: "'P1W' is not an ISO 8601 duration of the form PT1H30M"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := parseISODuration(tt.given)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_budgets(t *testing.T) {
+	workspace := clockify.Workspace{HourlyRate: clockify.HourlyRate{Amount: 5000, Currency: "USD"}}
+	projects := []clockify.Project{
+		{Name: "prod/cert-manager", Duration: "PT34H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT40H", Active: true}},
+		{Name: "website", Duration: "PT24H", HourlyRate: clockify.HourlyRate{Amount: 5000, Currency: "USD"},
+			BudgetEstimate: &clockify.ProjectBudgetEstimate{Estimate: 100000, Active: true}},
+		{Name: "admin", Duration: "PT10H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT100H", Active: true}},
+		{Name: "support", Duration: "PT90H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT20H", Active: true, ResetOption: "MONTHLY"}},
+		{Name: "inactive", Duration: "PT90H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT20H"}},
+		{Name: "archived", Duration: "PT90H", Archived: true, TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT20H", Active: true}},
+		{Name: "no-estimate"},
+	}
+
+	usages, err := computeBudgets(workspace, projects)
+	require.NoError(t, err)
+	assert.Equal(t, []budgetUsage{
+		{Project: "website", Kind: "budget", Used: "1200.00 USD", Limit: "1000.00 USD", Ratio: 1.2},
+		{Project: "prod/cert-manager", Kind: "estimate", Used: "34h", Limit: "40h", Ratio: 0.85},
+		{Project: "admin", Kind: "estimate", Used: "10h", Limit: "100h", Ratio: 0.1},
+	}, usages)
+
+	assert.Equal(t, "██████████████████████████░░░░  85%", progressBar(0.85))
+	assert.Equal(t, "██████████████████████████████ 120%", progressBar(1.2))
+
+	worked := map[string]bool{"website": true, "prod/cert-manager": true, "admin": true}
+	assert.Equal(t, []string{
+		"warning: website is over its budget (1200.00 USD of 1000.00 USD, 120%)",
+		"warning: prod/cert-manager used 85% of its estimate (34h of 40h)",
+	}, budgetWarnings(usages, []float64{80, 100}, worked))
+	assert.Equal(t, []string{
+		"warning: website is over its budget (1200.00 USD of 1000.00 USD, 120%)",
+	}, budgetWarnings(usages, []float64{100}, worked))
+	assert.Equal(t, []string(nil), budgetWarnings(usages, []float64{80, 100}, map[string]bool{"admin": true}))
+
+	_, err = computeBudgets(workspace, []clockify.Project{{Name: "broken", Duration: "PT34H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "40h", Active: true}}})
+	require.EqualError(t, err, "project 'broken': time estimate: '40h' is not an ISO 8601 duration of the form PT1H30M")
+}
+
+func Test_budgetThresholds(t *testing.T) {
+	assert.Equal(t, []float64{80, 100}, budgetThresholds(Budgets{}))
+	assert.Equal(t, []float64{90}, budgetThresholds(Budgets{Thresholds: []float64{90}}))
+	assert.Equal(t, []float64(nil), budgetThresholds(Budgets{Disabled: true}))
+}
+
+func Test_standupWarnings(t *testing.T) {
+	projects := []clockify.Project{
+		{Name: "prod/cert-manager", Duration: "PT34H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT40H", Active: true}},
+		{Name: "internal/hiring", Duration: "PT50H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "PT40H", Active: true}},
+	}
+	entries := []timeEntry{{Project: "prod/cert-manager"}, {Project: "internal/hiring"}}
+	conf := Config{ProjectAliases: map[string]string{"prod/cert-manager": "cert-manager"}, HideProjects: []string{"internal/hiring"}}
+	rw, err := newRewriter(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"warning: cert-manager used 85% of its estimate (34h of 40h)",
+	}, standupWarnings(conf, rw, clockify.Workspace{}, projects, entries))
+
+	broken := []clockify.Project{{Name: "prod/cert-manager", Duration: "PT34H", TimeEstimate: clockify.ProjectTimeEstimate{Estimate: "40h", Active: true}}}
+	assert.Nil(t, standupWarnings(conf, rw, clockify.Workspace{}, broken, entries))
+}
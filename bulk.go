@@ -0,0 +1,295 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// How many time entries 'clockidup bulk' updates at the same time.
+const defaultBulkConcurrency = 4
+
+// bulkOptions are the changes asked with the flags of 'clockidup bulk'.
+type bulkOptions struct {
+	Match *regexp.Regexp // Only the entries whose description matches.
+
+	Project *clockify.Project // Nil when the project is left as is.
+	Task    *clockify.Task    // Nil when the task is left as is.
+
+	Replace     *regexp.Regexp // Nil when the description is left as is.
+	ReplaceWith string         // Can refer to the groups, e.g. "#$1".
+
+	Billable *bool // Nil when the billable flag is left as is.
+}
+
+// bulkChange is the update of a time entry.
+type bulkChange struct {
+	Entry  clockify.TimeEntry
+	Update clockify.UpdateTimeEntryRequest
+}
+
+// parseReplace parses the value of --replace, e.g. 'PR (\d+)=>#$1'.
+func parseReplace(s string) (*regexp.Regexp, string, error) {
+	parts := strings.SplitN(s, "=>", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, "", fmt.Errorf("--replace must be of the form 'old=>new', got '%s'", s)
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("--replace: %s", err)
+	}
+	return re, parts[1], nil
+}
+
+// planBulk returns the updates of the entries that match and that would
+// change. The locked entries are returned separately since they can't be
+// updated. Changing the project removes the task unless a task is given.
+func planBulk(entries []clockify.TimeEntry, opts bulkOptions) (changes []bulkChange, locked []clockify.TimeEntry) {
+	for _, entry := range entries {
+		if opts.Match != nil && !opts.Match.MatchString(entry.Description) {
+			continue
+		}
+
+		update := entry.UpdateRequest()
+		if opts.Project != nil && update.ProjectID != opts.Project.ID {
+			update.ProjectID, update.TaskID = opts.Project.ID, ""
+		}
+		if opts.Task != nil {
+			update.TaskID = opts.Task.ID
+		}
+		if opts.Replace != nil {
+			update.Description = opts.Replace.ReplaceAllString(update.Description, opts.ReplaceWith)
+		}
+		if opts.Billable != nil {
+			update.Billable = *opts.Billable
+		}
+
+		if update.ProjectID == entry.ProjectID && update.TaskID == entry.TaskID &&
+			update.Description == entry.Description && update.Billable == entry.Billable {
+			continue
+		}
+		if entry.IsLocked {
+			locked = append(locked, entry)
+			continue
+		}
+		changes = append(changes, bulkChange{Entry: entry, Update: update})
+	}
+	return changes, locked
+}
+
+// bulkDiff returns the rows of the preview, one row per changed field, e.g.:
+//
+//  DATE               FIELD         BEFORE              AFTER
+//  2021-07-01 09:15   project       prod/cert-manager   oss/cert-manager
+//                     description   reviewing PR 3574   reviewing #3574
+func bulkDiff(changes []bulkChange, projectNames, taskNames map[string]string) [][]string {
+	var rows [][]string
+	for _, c := range changes {
+		date := c.Entry.TimeInterval.Start.Local().Format("2006-01-02 15:04")
+		add := func(field, before, after string) {
+			if before == after {
+				return
+			}
+			rows = append(rows, []string{date, field, before, after})
+			date = ""
+		}
+		add("project", orNone(projectNames[c.Entry.ProjectID]), orNone(projectNames[c.Update.ProjectID]))
+		add("task", orNone(taskNames[c.Entry.TaskID]), orNone(taskNames[c.Update.TaskID]))
+		add("description", c.Entry.Description, c.Update.Description)
+		add("billable", yesNo(c.Entry.Billable), yesNo(c.Update.Billable))
+	}
+	return rows
+}
+
+func orNone(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// applyBulk updates the time entries, a few at a time. All the entries are
+// tried even when some of them fail; the returned errors are in the same
+// order as the changes, and nil for the entries that were updated.
+func applyBulk(client clockifyClient, workspaceID string, changes []bulkChange, concurrency int) []error {
+	errs := make([]error, len(changes))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range changes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			c := changes[i]
+			_, err := client.UpdateTimeEntry(workspaceID, c.Entry.ID, c.Update)
+			if err != nil {
+				errs[i] = fmt.Errorf("while updating the time entry '%s' of %s: %s", c.Entry.Description, c.Entry.TimeInterval.Start.Local().Format("2006-01-02 15:04"), err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// confirmAndApply asks for a confirmation, unless yes is set, and then
+// updates the time entries. The failed updates are logged one by one.
+func confirmAndApply(client clockifyClient, workspaceID string, changes []bulkChange, yes bool, concurrency int) error {
+	if !yes {
+		confirmed := false
+		err := survey.AskOne(&survey.Confirm{
+			Message: fmt.Sprintf("Update these %d time entries?", len(changes)),
+			Default: false,
+		}, &confirmed)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, err := range applyBulk(client, workspaceID, changes, concurrency) {
+		if err != nil {
+			logutil.Errorf("%s", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d time entries could not be updated", failed, len(changes))
+	}
+	logutil.Infof("updated %d time entries, run 'clockidup undo %d' to revert", len(changes), len(changes))
+	return nil
+}
+
+func runBulk(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("bulk", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	matchFlag := flags.String("match", "", "Only change the entries whose description matches this regular expression.")
+	projectFlag := flags.String("set-project", "", "Move the entries to this project.")
+	taskFlag := flags.String("set-task", "", "Move the entries to this task of the project given with --set-project.")
+	replaceFlag := flags.String("replace", "", "Rewrite the descriptions, e.g. 'PR (\\d+)=>#$1'.")
+	billableFlag := flags.Bool("set-billable", false, "Make the entries billable, or non-billable with --set-billable=false.")
+	yesFlag := flags.Bool("yes", false, "Don't ask for confirmation.")
+	concurrency := flags.Int("concurrency", defaultBulkConcurrency, "How many entries are updated at the same time.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required, e.g. 'clockidup bulk --from 2021-07-01 --match 'cert-manager' --set-project oss/cert-manager'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", *concurrency)
+	}
+
+	var opts bulkOptions
+	if *matchFlag != "" {
+		opts.Match, err = regexp.Compile(*matchFlag)
+		if err != nil {
+			return fmt.Errorf("--match: %s", err)
+		}
+	}
+	if *replaceFlag != "" {
+		opts.Replace, opts.ReplaceWith, err = parseReplace(*replaceFlag)
+		if err != nil {
+			return err
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "set-billable" {
+			opts.Billable = billableFlag
+		}
+	})
+	if *taskFlag != "" && *projectFlag == "" {
+		return fmt.Errorf("--set-task needs --set-project since the tasks belong to a project")
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+	if *projectFlag != "" {
+		project, err := resolveProject(client, workspace.ID, *projectFlag)
+		if err != nil {
+			return err
+		}
+		opts.Project = &project
+	}
+	if *taskFlag != "" {
+		task, err := resolveTask(client, workspace.ID, opts.Project.ID, *taskFlag)
+		if err != nil {
+			return err
+		}
+		opts.Task = &task
+	}
+	if opts.Project == nil && opts.Replace == nil && opts.Billable == nil {
+		return fmt.Errorf("nothing to change, use --set-project, --replace or --set-billable")
+	}
+
+	entries, err := client.TimeEntries(workspace.ID, workspace.Memberships[0].UserID, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %s", err)
+	}
+	changes, locked := planBulk(entries, opts)
+	for _, entry := range locked {
+		logutil.Infof("the time entry '%s' of %s is locked and is left as is", entry.Description, entry.TimeInterval.Start.Local().Format(layoutISO))
+	}
+	if len(changes) == 0 {
+		fmt.Println("No time entry to change.")
+		return nil
+	}
+
+	projects, err := client.Projects(workspace.ID)
+	if err != nil {
+		return fmt.Errorf("while fetching projects: %s", err)
+	}
+	projectNames := make(map[string]string)
+	for _, p := range projects {
+		projectNames[p.ID] = p.Name
+	}
+	taskNames := make(map[string]string)
+	for _, c := range changes {
+		if c.Entry.TaskID == "" || taskNames[c.Entry.TaskID] != "" {
+			continue
+		}
+		task, err := client.Task(workspace.ID, c.Entry.ProjectID, c.Entry.TaskID)
+		if err != nil {
+			return fmt.Errorf("while fetching task for time entry '%s': %s", c.Entry.Description, err)
+		}
+		taskNames[task.ID] = task.Name
+	}
+	if opts.Task != nil {
+		taskNames[opts.Task.ID] = opts.Task.Name
+	}
+
+	err = printTable(os.Stdout, []string{"DATE", "FIELD", "BEFORE", "AFTER"}, bulkDiff(changes, projectNames, taskNames))
+	if err != nil {
+		return err
+	}
+
+	return confirmAndApply(client, workspace.ID, changes, *yesFlag, *concurrency)
+}
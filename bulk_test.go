@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+func Test_parseReplace(t *testing.T) {
+	re, with, err := parseReplace(`PR (\d+)=>#$1`)
+	require.NoError(t, err)
+	assert.Equal(t, "reviewing #3574", re.ReplaceAllString("reviewing PR 3574", with))
+
+	_, _, err = parseReplace("PR")
+	require.EqualError(t, err, "--replace must be of the form 'old=>new', got 'PR'")
+
+	_, _, err = parseReplace("(=>x")
+	require.EqualError(t, err, "--replace: error parsing regexp: missing closing ): `(`")
+}
+
+func Test_planBulk(t *testing.T) {
+	entries := []clockify.TimeEntry{
+		{ID: "entry-1-uid", Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid"},
+		{ID: "entry-2-uid", Description: "expenses", ProjectID: "project-2-uid"},
+		{ID: "entry-3-uid", Description: "reviewing PR 3575", ProjectID: "project-1-uid", IsLocked: true},
+		{ID: "entry-4-uid", Description: "reviewing #3576", ProjectID: "project-3-uid"},
+	}
+	billable := true
+
+	t.Run("re-project and rename the matching entries", func(t *testing.T) {
+		re, with, err := parseReplace(`PR (\d+)=>#$1`)
+		require.NoError(t, err)
+
+		changes, locked := planBulk(entries, bulkOptions{
+			Match:       regexp.MustCompile("^reviewing"),
+			Project:     &clockify.Project{ID: "project-3-uid"},
+			Replace:     re,
+			ReplaceWith: with,
+		})
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, "entry-1-uid", changes[0].Entry.ID)
+		assert.Equal(t, "project-3-uid", changes[0].Update.ProjectID)
+		assert.Equal(t, "", changes[0].Update.TaskID, "the task of the previous project is removed")
+		assert.Equal(t, "reviewing #3574", changes[0].Update.Description)
+		require.Len(t, locked, 1)
+		assert.Equal(t, "entry-3-uid", locked[0].ID)
+	})
+
+	t.Run("the entries that would not change are left out", func(t *testing.T) {
+		changes, locked := planBulk(entries, bulkOptions{Billable: &billable, Match: regexp.MustCompile("expenses")})
+		require.Len(t, changes, 1)
+		assert.Equal(t, "entry-2-uid", changes[0].Entry.ID)
+		assert.True(t, changes[0].Update.Billable)
+		assert.Empty(t, locked)
+
+		changes, _ = planBulk(entries, bulkOptions{Project: &clockify.Project{ID: "project-3-uid"}, Match: regexp.MustCompile("3576")})
+		assert.Empty(t, changes)
+	})
+}
+
+func Test_bulkDiff(t *testing.T) {
+	entry := clockify.TimeEntry{ID: "entry-1-uid", Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid"}
+	entry.TimeInterval.Start = mustParse("2021-07-01T09:15:00Z")
+	update := entry.UpdateRequest()
+	update.ProjectID, update.TaskID, update.Description = "project-2-uid", "", "reviewing #3574"
+
+	got := bulkDiff([]bulkChange{{Entry: entry, Update: update}}, map[string]string{"project-1-uid": "prod/cert-manager", "project-2-uid": "oss/cert-manager"}, map[string]string{"task-1-uid": "1.2"})
+
+	date := entry.TimeInterval.Start.Local().Format("2006-01-02 15:04")
+	assert.Equal(t, [][]string{
+		{date, "project", "prod/cert-manager", "oss/cert-manager"},
+		{"", "task", "1.2", "-"},
+		{"", "description", "reviewing PR 3574", "reviewing #3574"},
+	}, got)
+}
+
+func Test_applyBulk(t *testing.T) {
+	var changes []bulkChange
+	for i := 1; i <= 5; i++ {
+		entry := clockify.TimeEntry{ID: fmt.Sprintf("entry-%d-uid", i), Description: fmt.Sprintf("entry %d", i)}
+		update := entry.UpdateRequest()
+		update.Billable = true
+		changes = append(changes, bulkChange{Entry: entry, Update: update})
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockclockifyClient(ctrl)
+	for _, c := range changes {
+		call := client.EXPECT().UpdateTimeEntry("workspace-1-uid", c.Entry.ID, c.Update)
+		if c.Entry.ID == "entry-3-uid" {
+			call.Return(clockify.TimeEntry{}, fmt.Errorf("400 Bad Request: Time entry is locked"))
+		} else {
+			call.Return(clockify.TimeEntry{ID: c.Entry.ID}, nil)
+		}
+	}
+
+	errs := applyBulk(client, "workspace-1-uid", changes, 2)
+
+	require.Len(t, errs, 5)
+	for i, err := range errs {
+		if i == 2 {
+			assert.EqualError(t, err, "while updating the time entry 'entry 3' of "+changes[2].Entry.TimeInterval.Start.Local().Format("2006-01-02 15:04")+": 400 Bad Request: Time entry is locked")
+			continue
+		}
+		assert.NoError(t, err)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/maelvls/clockidup/logutil"
@@ -122,12 +124,12 @@ type Workspace struct {
 	FeatureSubscriptionType interface{}       `json:"featureSubscriptionType"`
 }
 type HourlyRate struct {
-	Amount   int    `json:"amount"`
+	Amount   int    `json:"amount"` // In cents, e.g. 12050 is 120.50.
 	Currency string `json:"currency"`
 }
 type Memberships struct {
 	UserID           string      `json:"userId"`
-	HourlyRate       interface{} `json:"hourlyRate"`
+	HourlyRate       *HourlyRate `json:"hourlyRate"` // Nil when the member has no rate of their own.
 	CostRate         interface{} `json:"costRate"`
 	TargetID         string      `json:"targetId"`
 	MembershipType   string      `json:"membershipType"`
@@ -206,23 +208,23 @@ func (c *Clockify) Workspaces() ([]Workspace, error) {
 }
 
 type Project struct {
-	ID             string              `json:"id"`
-	Name           string              `json:"name"`
-	HourlyRate     HourlyRate          `json:"hourlyRate"`
-	ClientID       string              `json:"clientId"`
-	WorkspaceID    string              `json:"workspaceId"`
-	Billable       bool                `json:"billable"`
-	Memberships    []Memberships       `json:"memberships"`
-	Color          string              `json:"color"`
-	Archived       bool                `json:"archived"`
-	Duration       string              `json:"duration"`
-	ClientName     string              `json:"clientName"`
-	Note           string              `json:"note"`
-	CostRate       interface{}         `json:"costRate"`
-	TimeEstimate   ProjectTimeEstimate `json:"timeEstimate"`
-	BudgetEstimate interface{}         `json:"budgetEstimate"`
-	Public         bool                `json:"public"`
-	Template       bool                `json:"template"`
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	HourlyRate     HourlyRate             `json:"hourlyRate"`
+	ClientID       string                 `json:"clientId"`
+	WorkspaceID    string                 `json:"workspaceId"`
+	Billable       bool                   `json:"billable"`
+	Memberships    []Memberships          `json:"memberships"`
+	Color          string                 `json:"color"`
+	Archived       bool                   `json:"archived"`
+	Duration       string                 `json:"duration"`
+	ClientName     string                 `json:"clientName"`
+	Note           string                 `json:"note"`
+	CostRate       interface{}            `json:"costRate"`
+	TimeEstimate   ProjectTimeEstimate    `json:"timeEstimate"`
+	BudgetEstimate *ProjectBudgetEstimate `json:"budgetEstimate"` // Nil when the project has no budget.
+	Public         bool                   `json:"public"`
+	Template       bool                   `json:"template"`
 }
 type ProjectHourlyRate struct {
 	Amount   int    `json:"amount"`
@@ -237,16 +239,23 @@ type ProjectMemberships struct {
 	MembershipStatus string      `json:"membershipStatus"`
 }
 type ProjectTimeEstimate struct {
-	Estimate    string      `json:"estimate"`
-	Type        string      `json:"type"`
-	ResetOption interface{} `json:"resetOption"`
-	Active      bool        `json:"active"`
+	Estimate    string `json:"estimate"`    // ISO 8601 duration, e.g. "PT40H".
+	Type        string `json:"type"`        // Either "AUTO" or "MANUAL".
+	ResetOption string `json:"resetOption"` // Empty or "MONTHLY".
+	Active      bool   `json:"active"`
+}
+type ProjectBudgetEstimate struct {
+	Estimate    int64  `json:"estimate"` // In cents.
+	Type        string `json:"type"`
+	ResetOption string `json:"resetOption"`
+	Active      bool   `json:"active"`
 }
 
 var ErrEmptyWorkspaceID = fmt.Errorf("workspaceID is empty")
 var ErrEmptyUserID = fmt.Errorf("userID is empty")
 var ErrEmptyTaskID = fmt.Errorf("taskID is empty")
 var ErrEmptyProjectID = fmt.Errorf("projectID is empty")
+var ErrEmptyTimeEntryID = fmt.Errorf("timeEntryID is empty")
 
 // May return ErrClockify, ErrUnexpect, or ErrEmptyWorkspaceID.
 func (c *Clockify) Projects(workspaceID string) ([]Project, error) {
@@ -254,56 +263,39 @@ func (c *Clockify) Projects(workspaceID string) ([]Project, error) {
 		return nil, ErrEmptyWorkspaceID
 	}
 
-	path := fmt.Sprintf("/api/v1/workspaces/%s/projects", workspaceID)
-	req, err := http.NewRequest("GET", c.server+path, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating HTTP request for GET %s: %w", path, err)
-	}
-
-	httpResp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("while calling GET %s: %w", path, err)
-	}
-	defer httpResp.Body.Close()
-
-	bytes, err := ioutil.ReadAll(httpResp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("while reading HTTP response for GET %s: %w", path, err)
-	}
-
-	switch httpResp.StatusCode {
-	case 200:
-		// continue below
-	default:
-		errClockify := ErrClockify{Status: httpResp.StatusCode}
-		err = json.Unmarshal(bytes, &errClockify)
-		if err != nil {
-			return nil, ErrUnexpect{RawResponseBody: string(bytes), Status: httpResp.StatusCode}
-		}
-		return nil, errClockify
-	}
-
 	var projects []Project
-	err = json.Unmarshal(bytes, &projects)
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/projects?page-size=%d", workspaceID, listPageSize), nil, &projects)
 	if err != nil {
-		return nil, fmt.Errorf("while parsing JSON from the HTTP response for GET %s: %w", path, err)
+		return nil, err
 	}
 
 	return projects, nil
 }
 
 type TimeEntry struct {
-	ID                string        `json:"id"`
-	Description       string        `json:"description"`
-	TagIds            []interface{} `json:"tagIds"`
-	UserID            string        `json:"userId"`
-	Billable          bool          `json:"billable"`
-	TaskID            string        `json:"taskId"`
-	ProjectID         string        `json:"projectId"`
-	TimeInterval      TimeInterval  `json:"timeInterval"`
-	WorkspaceID       string        `json:"workspaceId"`
-	IsLocked          bool          `json:"isLocked"`
-	CustomFieldValues interface{}   `json:"customFieldValues"`
+	ID                string             `json:"id"`
+	Description       string             `json:"description"`
+	TagIds            []string           `json:"tagIds"`
+	UserID            string             `json:"userId"`
+	Billable          bool               `json:"billable"`
+	TaskID            string             `json:"taskId"`
+	ProjectID         string             `json:"projectId"`
+	TimeInterval      TimeInterval       `json:"timeInterval"`
+	WorkspaceID       string             `json:"workspaceId"`
+	IsLocked          bool               `json:"isLocked"`
+	CustomFieldValues []CustomFieldValue `json:"customFieldValues"`
+}
+
+// CustomFieldValue is the value of a custom field on a time entry. The Value
+// depends on the Type of the field: a string for "TXT", "LINK" and
+// "DROPDOWN_SINGLE", a float64 for "NUMBER", a bool for "CHECKBOX", and a
+// list of strings for "DROPDOWN_MULTIPLE".
+type CustomFieldValue struct {
+	CustomFieldID string      `json:"customFieldId"`
+	TimeEntryID   string      `json:"timeEntryId"`
+	Value         interface{} `json:"value"`
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
 }
 type TimeInterval struct {
 	Start    time.Time `json:"start"`
@@ -311,7 +303,14 @@ type TimeInterval struct {
 	Duration string    `json:"duration"`
 }
 
+// The Clockify API returns at most 50 time entries per page when the
+// "page-size" query parameter is not given.
+const defaultPageSize = 50
+
 // May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or ErrEmptyUserID.
+//
+// When there are more than 50 time entries between start and end, the
+// following pages are fetched until an incomplete page is returned.
 func (c *Clockify) TimeEntries(workspaceID, userID string, start, end time.Time) ([]TimeEntry, error) {
 	if workspaceID == "" {
 		return nil, ErrEmptyWorkspaceID
@@ -330,6 +329,55 @@ func (c *Clockify) TimeEntries(workspaceID, userID string, start, end time.Time)
 		end.UTC().Format(time.RFC3339),
 	)
 
+	var all []TimeEntry
+	for page := 1; ; page++ {
+		// The first page is requested without the "page" query parameter
+		// so that the URL stays the same as when the pagination did not
+		// exist.
+		pagePath := path
+		if page > 1 {
+			pagePath = fmt.Sprintf("%s&page=%d", path, page)
+		}
+
+		timeEntries, err := c.timeEntriesPage(pagePath)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, timeEntries...)
+
+		if len(timeEntries) < defaultPageSize {
+			break
+		}
+	}
+
+	return all, nil
+}
+
+// InProgressTimeEntry returns the timer that the user hasn't stopped yet,
+// whenever it was started. It returns nil when no timer is running.
+//
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or ErrEmptyUserID.
+func (c *Clockify) InProgressTimeEntry(workspaceID, userID string) (*TimeEntry, error) {
+	if workspaceID == "" {
+		return nil, ErrEmptyWorkspaceID
+	}
+	if userID == "" {
+		return nil, ErrEmptyUserID
+	}
+
+	var timeEntries []TimeEntry
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/user/%s/time-entries?in-progress=true", workspaceID, userID), nil, &timeEntries)
+	if err != nil {
+		return nil, err
+	}
+	if len(timeEntries) == 0 {
+		return nil, nil
+	}
+
+	return &timeEntries[0], nil
+}
+
+func (c *Clockify) timeEntriesPage(path string) ([]TimeEntry, error) {
 	req, err := http.NewRequest("GET", c.server+path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating HTTP request for GET %s: %w", path, err)
@@ -434,6 +482,338 @@ func (c *Clockify) Task(workspaceID, projectID, taskID string) (Task, error) {
 	return task, nil
 }
 
+type Tag struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspaceId"`
+	Archived    bool   `json:"archived"`
+}
+
+type CustomField struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"` // For example "TXT", "NUMBER" or "DROPDOWN_SINGLE".
+	Description   string   `json:"description"`
+	Placeholder   string   `json:"placeholder"`
+	AllowedValues []string `json:"allowedValues"`
+	Status        string   `json:"status"` // Either "VISIBLE", "INACTIVE" or "INVISIBLE".
+	WorkspaceID   string   `json:"workspaceId"`
+}
+
+type Client struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	WorkspaceID string `json:"workspaceId"`
+	Archived    bool   `json:"archived"`
+	Note        string `json:"note"`
+}
+
+// The list endpoints below return 50 items per page by default. Since the
+// number of tasks, tags and clients is usually small, we ask for a single
+// large page instead of paginating.
+const listPageSize = 1000
+
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or
+// ErrEmptyProjectID.
+func (c *Clockify) Tasks(workspaceID, projectID string) ([]Task, error) {
+	if workspaceID == "" {
+		return nil, ErrEmptyWorkspaceID
+	}
+	if projectID == "" {
+		return nil, ErrEmptyProjectID
+	}
+
+	var tasks []Task
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/projects/%s/tasks?page-size=%d", workspaceID, projectID, listPageSize), nil, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) Tags(workspaceID string) ([]Tag, error) {
+	if workspaceID == "" {
+		return nil, ErrEmptyWorkspaceID
+	}
+
+	var tags []Tag
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/tags?page-size=%d", workspaceID, listPageSize), nil, &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) Clients(workspaceID string) ([]Client, error) {
+	if workspaceID == "" {
+		return nil, ErrEmptyWorkspaceID
+	}
+
+	var clients []Client
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/clients?page-size=%d", workspaceID, listPageSize), nil, &clients)
+	if err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) CustomFields(workspaceID string) ([]CustomField, error) {
+	if workspaceID == "" {
+		return nil, ErrEmptyWorkspaceID
+	}
+
+	var fields []CustomField
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/custom-fields", workspaceID), nil, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or
+// ErrEmptyTimeEntryID.
+func (c *Clockify) TimeEntry(workspaceID, timeEntryID string) (TimeEntry, error) {
+	if workspaceID == "" {
+		return TimeEntry{}, ErrEmptyWorkspaceID
+	}
+	if timeEntryID == "" {
+		return TimeEntry{}, ErrEmptyTimeEntryID
+	}
+
+	var timeEntry TimeEntry
+	err := c.do("GET", fmt.Sprintf("/api/v1/workspaces/%s/time-entries/%s", workspaceID, timeEntryID), nil, &timeEntry)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	return timeEntry, nil
+}
+
+// UpdateTimeEntryRequest is the body of the PUT request that updates a time
+// entry. Clockify replaces the whole time entry, meaning that the fields left
+// empty are cleared. To only change some fields, start from
+// TimeEntry.UpdateRequest.
+type UpdateTimeEntryRequest struct {
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"` // Nil when the timer is still running.
+	Billable    bool       `json:"billable"`
+	Description string     `json:"description"`
+	ProjectID   string     `json:"projectId,omitempty"`
+	TaskID      string     `json:"taskId,omitempty"`
+	TagIds      []string   `json:"tagIds"`
+
+	CustomFields []UpdateCustomField `json:"customFields,omitempty"`
+}
+
+type UpdateCustomField struct {
+	CustomFieldID string      `json:"customFieldId"`
+	Value         interface{} `json:"value"`
+}
+
+// UpdateRequest returns the request that would leave the time entry as it
+// is.
+func (e TimeEntry) UpdateRequest() UpdateTimeEntryRequest {
+	var end *time.Time
+	if !e.TimeInterval.End.IsZero() {
+		end = &e.TimeInterval.End
+	}
+	// The custom fields must be given back, otherwise they are cleared.
+	var fields []UpdateCustomField
+	for _, v := range e.CustomFieldValues {
+		fields = append(fields, UpdateCustomField{CustomFieldID: v.CustomFieldID, Value: v.Value})
+	}
+	return UpdateTimeEntryRequest{
+		Start:        e.TimeInterval.Start,
+		End:          end,
+		Billable:     e.Billable,
+		Description:  e.Description,
+		ProjectID:    e.ProjectID,
+		TaskID:       e.TaskID,
+		TagIds:       e.TagIds,
+		CustomFields: fields,
+	}
+}
+
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or
+// ErrEmptyTimeEntryID.
+func (c *Clockify) UpdateTimeEntry(workspaceID, timeEntryID string, update UpdateTimeEntryRequest) (TimeEntry, error) {
+	if workspaceID == "" {
+		return TimeEntry{}, ErrEmptyWorkspaceID
+	}
+	if timeEntryID == "" {
+		return TimeEntry{}, ErrEmptyTimeEntryID
+	}
+
+	var timeEntry TimeEntry
+	err := c.do("PUT", fmt.Sprintf("/api/v1/workspaces/%s/time-entries/%s", workspaceID, timeEntryID), update, &timeEntry)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	return timeEntry, nil
+}
+
+// CreateTimeEntryRequest is the body of the POST request that creates a
+// time entry. The entry is created for the owner of the token.
+type CreateTimeEntryRequest struct {
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"` // Nil starts a timer.
+	Billable    bool       `json:"billable"`
+	Description string     `json:"description"`
+	ProjectID   string     `json:"projectId,omitempty"`
+	TaskID      string     `json:"taskId,omitempty"`
+	TagIds      []string   `json:"tagIds,omitempty"`
+
+	CustomFields []UpdateCustomField `json:"customFields,omitempty"`
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) CreateTimeEntry(workspaceID string, create CreateTimeEntryRequest) (TimeEntry, error) {
+	if workspaceID == "" {
+		return TimeEntry{}, ErrEmptyWorkspaceID
+	}
+
+	var timeEntry TimeEntry
+	err := c.do("POST", fmt.Sprintf("/api/v1/workspaces/%s/time-entries", workspaceID), create, &timeEntry)
+	if err != nil {
+		return TimeEntry{}, err
+	}
+
+	return timeEntry, nil
+}
+
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or
+// ErrEmptyTimeEntryID.
+func (c *Clockify) DeleteTimeEntry(workspaceID, timeEntryID string) error {
+	if workspaceID == "" {
+		return ErrEmptyWorkspaceID
+	}
+	if timeEntryID == "" {
+		return ErrEmptyTimeEntryID
+	}
+
+	return c.do("DELETE", fmt.Sprintf("/api/v1/workspaces/%s/time-entries/%s", workspaceID, timeEntryID), nil, nil)
+}
+
+// The projects, tasks and tags created by clockidup only have a name; the
+// rest (color, client, estimate...) can be set in Clockify afterwards.
+type createNamedRequest struct {
+	Name string `json:"name"`
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) CreateProject(workspaceID, name string) (Project, error) {
+	if workspaceID == "" {
+		return Project{}, ErrEmptyWorkspaceID
+	}
+
+	var project Project
+	err := c.do("POST", fmt.Sprintf("/api/v1/workspaces/%s/projects", workspaceID), createNamedRequest{Name: name}, &project)
+	if err != nil {
+		return Project{}, err
+	}
+
+	return project, nil
+}
+
+// May return ErrClockify, ErrUnexpect, ErrEmptyWorkspaceID or
+// ErrEmptyProjectID.
+func (c *Clockify) CreateTask(workspaceID, projectID, name string) (Task, error) {
+	if workspaceID == "" {
+		return Task{}, ErrEmptyWorkspaceID
+	}
+	if projectID == "" {
+		return Task{}, ErrEmptyProjectID
+	}
+
+	var task Task
+	err := c.do("POST", fmt.Sprintf("/api/v1/workspaces/%s/projects/%s/tasks", workspaceID, projectID), createNamedRequest{Name: name}, &task)
+	if err != nil {
+		return Task{}, err
+	}
+
+	return task, nil
+}
+
+// May return ErrClockify, ErrUnexpect or ErrEmptyWorkspaceID.
+func (c *Clockify) CreateTag(workspaceID, name string) (Tag, error) {
+	if workspaceID == "" {
+		return Tag{}, ErrEmptyWorkspaceID
+	}
+
+	var tag Tag
+	err := c.do("POST", fmt.Sprintf("/api/v1/workspaces/%s/tags", workspaceID), createNamedRequest{Name: name}, &tag)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// do calls the Clockify API and decodes the JSON response into 'out' when
+// 'out' is not nil. When 'in' is not nil, it is encoded to JSON and sent as
+// the request body. May return ErrClockify or ErrUnexpect.
+func (c *Clockify) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		bytes, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("while encoding the JSON body for %s %s: %w", method, path, err)
+		}
+		body = strings.NewReader(string(bytes))
+	}
+
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return fmt.Errorf("creating HTTP request for %s %s: %w", method, path, err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("while doing %s %s: %w", method, path, err)
+	}
+	defer httpResp.Body.Close()
+
+	bytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("while reading HTTP response for %s %s: %w", method, path, err)
+	}
+
+	switch httpResp.StatusCode {
+	case 200, 201, 204:
+		// continue below
+	default:
+		errClockify := ErrClockify{Status: httpResp.StatusCode}
+		err = json.Unmarshal(bytes, &errClockify)
+		if err != nil {
+			return ErrUnexpect{RawResponseBody: string(bytes), Status: httpResp.StatusCode}
+		}
+		return errClockify
+	}
+
+	if out == nil || len(bytes) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(bytes, out)
+	if err != nil {
+		logutil.Debugf("body: %s", bytes)
+		return fmt.Errorf("while parsing JSON from the HTTP response for %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
 type transport struct {
 	trWrapped http.RoundTripper
 	token     string
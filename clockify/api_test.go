@@ -3,7 +3,9 @@ package clockify
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
 	"strings"
@@ -89,7 +91,7 @@ func TestClockify_Projects(t *testing.T) {
 			Billable:    true,
 			Memberships: []Memberships{{
 				UserID:           "60e086c24f27a949c058082d",
-				HourlyRate:       interface{}(nil),
+				HourlyRate:       nil,
 				CostRate:         interface{}(nil),
 				TargetID:         "60e0a9cf5f596c5a7d10d821",
 				MembershipType:   "PROJECT",
@@ -117,7 +119,7 @@ func TestClockify_Projects(t *testing.T) {
 			Billable:    true,
 			Memberships: []Memberships{{
 				UserID:           "60e086c24f27a949c058082d",
-				HourlyRate:       interface{}(nil),
+				HourlyRate:       nil,
 				CostRate:         interface{}(nil),
 				TargetID:         "60f4681a0fdfe402db9afae9",
 				MembershipType:   "PROJECT",
@@ -148,6 +150,43 @@ func TestClockify_Projects(t *testing.T) {
 		assert.Equal(t, []Project(nil), got)
 	})
 
+	t.Run("the hourly rates of the members are decoded", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"id":"project-1-uid","name":"project-1","hourlyRate":{"amount":8000,"currency":"EUR"},"memberships":[{"userId":"user-1-uid","hourlyRate":{"amount":9500,"currency":"EUR"}},{"userId":"user-2-uid","hourlyRate":null}]}]`))
+		})
+
+		got, gotErr := clockify.Projects("workspace-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, []Project{{
+			ID:         "project-1-uid",
+			Name:       "project-1",
+			HourlyRate: HourlyRate{Amount: 8000, Currency: "EUR"},
+			Memberships: []Memberships{
+				{UserID: "user-1-uid", HourlyRate: &HourlyRate{Amount: 9500, Currency: "EUR"}},
+				{UserID: "user-2-uid"},
+			},
+		}}, got)
+	})
+
+	t.Run("more than 50 projects are returned", func(t *testing.T) {
+		var body []string
+		for i := 1; i <= 60; i++ {
+			body = append(body, fmt.Sprintf(`{"id":"project-%d-uid","name":"project-%d"}`, i, i))
+		}
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/projects", r.URL.Path)
+			assert.Equal(t, "1000", r.URL.Query().Get("page-size"))
+			_, _ = w.Write([]byte("[" + strings.Join(body, ",") + "]"))
+		})
+
+		got, gotErr := clockify.Projects("workspace-1-uid")
+
+		require.NoError(t, gotErr)
+		require.Len(t, got, 60)
+		assert.Equal(t, Project{ID: "project-60-uid", Name: "project-60"}, got[59])
+	})
+
 	t.Run("empty project id", func(t *testing.T) {
 		clockify := NewClient(withToken(t), WithClient(&http.Client{Transport: tr}))
 
@@ -242,7 +281,7 @@ func TestClockify_TimeEntries(t *testing.T) {
 		assert.Equal(t, []TimeEntry([]TimeEntry{{
 			ID:          "60f467daf547ce2601a54b7f",
 			Description: "work with no project",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    false,
 			TaskID:      "",
@@ -254,11 +293,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60f467cbd5588e20a966c8d2",
 			Description: "some work with project but no task",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    true,
 			TaskID:      "",
@@ -270,11 +309,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60f467bbf547ce2601a54b59",
 			Description: "unit-test of clockidup, work with project and task",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    true,
 			TaskID:      "60e0a9f00afa073620eade56",
@@ -286,11 +325,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60e0ccf4909afe51901a154c",
 			Description: "work with no project",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    false,
 			TaskID:      "",
@@ -302,11 +341,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60e0cccf909afe51901a151c",
 			Description: "some work with project but no task",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    false,
 			TaskID:      "",
@@ -318,11 +357,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60e0ccbc4f27a949c058498b",
 			Description: "unit-test of clockidup, work with project and task",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    true,
 			TaskID:      "60e0a9f00afa073620eade56",
@@ -334,11 +373,11 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}, {
 			ID:          "60f4681ba18d2d6d98bdab2c",
 			Description: "work on project-2",
-			TagIds:      []interface{}{},
+			TagIds:      []string{},
 			UserID:      "60e086c24f27a949c058082d",
 			Billable:    true,
 			TaskID:      "",
@@ -350,7 +389,7 @@ func TestClockify_TimeEntries(t *testing.T) {
 			},
 			WorkspaceID:       "60e086c24f27a949c058082e",
 			IsLocked:          false,
-			CustomFieldValues: interface{}(nil),
+			CustomFieldValues: nil,
 		}}), got)
 	})
 	t.Run("the requested workspace does not exist", func(t *testing.T) {
@@ -405,7 +444,58 @@ func TestClockify_TimeEntries(t *testing.T) {
 		require.Equal(t, gotErr, ErrEmptyUserID)
 		assert.Equal(t, []TimeEntry(nil), got)
 	})
+	t.Run("more than 50 time entries are returned", func(t *testing.T) {
+		var pages []string
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/user/user-1-uid/time-entries", r.URL.Path)
+			page := r.URL.Query().Get("page")
+			pages = append(pages, page)
+
+			count := 50
+			if page == "2" {
+				count = 10
+			}
+			var body []string
+			for i := 0; i < count; i++ {
+				body = append(body, fmt.Sprintf(`{"id":"entry-%s-%d-uid"}`, page, i))
+			}
+			_, _ = w.Write([]byte("[" + strings.Join(body, ",") + "]"))
+		})
+
+		got, gotErr := clockify.TimeEntries("workspace-1-uid", "user-1-uid", mustParse("2021-07-01 00:00:00"), mustParse("2021-07-31 23:59:00"))
 
+		require.NoError(t, gotErr)
+		assert.Len(t, got, 60)
+		assert.Equal(t, "entry-2-9-uid", got[59].ID)
+		assert.Equal(t, []string{"", "2"}, pages)
+	})
+}
+
+func TestClockify_InProgressTimeEntry(t *testing.T) {
+	t.Run("a timer is running", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/user/user-1-uid/time-entries", r.URL.Path)
+			assert.Equal(t, "true", r.URL.Query().Get("in-progress"))
+			_, _ = w.Write([]byte(`[{"id":"entry-1-uid","timeInterval":{"start":"2021-06-01T09:00:00Z","end":null}}]`))
+		})
+
+		got, gotErr := clockify.InProgressTimeEntry("workspace-1-uid", "user-1-uid")
+
+		require.NoError(t, gotErr)
+		require.NotNil(t, got)
+		assert.Equal(t, "entry-1-uid", got.ID)
+		assert.True(t, got.TimeInterval.End.IsZero())
+	})
+	t.Run("no timer is running", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[]`))
+		})
+
+		got, gotErr := clockify.InProgressTimeEntry("workspace-1-uid", "user-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Nil(t, got)
+	})
 }
 
 func withToken(t *testing.T) (token string) {
@@ -620,3 +710,380 @@ func TestIs(t *testing.T) {
 		})
 	}
 }
+
+// withFakeServer returns a client that talks to a local stand-in for the
+// Clockify API. Unlike withReplayTransport, it is meant for the endpoints
+// for which we don't have recorded interactions.
+func withFakeServer(t *testing.T, handler http.HandlerFunc) *Clockify {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient("token", WithServer(server.URL))
+}
+
+func TestClockify_Tasks(t *testing.T) {
+	t.Run("the tasks of the project are returned", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/projects/project-1-uid/tasks", r.URL.Path)
+			assert.Equal(t, "1000", r.URL.Query().Get("page-size"))
+			assert.Equal(t, "token", r.Header.Get("X-Api-Key"))
+			_, _ = w.Write([]byte(`[{"id":"task-1-uid","name":"task-1","projectId":"project-1-uid","assigneeIds":[],"estimate":"PT0S","status":"ACTIVE","duration":"PT1H"}]`))
+		})
+
+		got, gotErr := clockify.Tasks("workspace-1-uid", "project-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, []Task{{
+			ID:          "task-1-uid",
+			Name:        "task-1",
+			ProjectID:   "project-1-uid",
+			AssigneeIds: []string{},
+			Estimate:    "PT0S",
+			Status:      "ACTIVE",
+			Duration:    "PT1H",
+		}}, got)
+	})
+
+	t.Run("the project does not exist", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+			_, _ = w.Write([]byte(`{"message":"PROJECT with ID 'dummy' not found.","code":501}`))
+		})
+
+		got, gotErr := clockify.Tasks("workspace-1-uid", "dummy")
+
+		require.EqualError(t, gotErr, "404 Not Found: PROJECT with ID 'dummy' not found.")
+		assert.Equal(t, []Task(nil), got)
+	})
+
+	t.Run("empty ids", func(t *testing.T) {
+		clockify := NewClient("token")
+
+		_, gotErr := clockify.Tasks("", "project-1-uid")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+
+		_, gotErr = clockify.Tasks("workspace-1-uid", "")
+		require.Equal(t, ErrEmptyProjectID, gotErr)
+	})
+}
+
+func TestClockify_Tags(t *testing.T) {
+	t.Run("the tags of the workspace are returned", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/tags", r.URL.Path)
+			_, _ = w.Write([]byte(`[{"id":"tag-1-uid","name":"blocker","workspaceId":"workspace-1-uid","archived":false}]`))
+		})
+
+		got, gotErr := clockify.Tags("workspace-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, []Tag{{ID: "tag-1-uid", Name: "blocker", WorkspaceID: "workspace-1-uid"}}, got)
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(403)
+		})
+
+		got, gotErr := clockify.Tags("dummy")
+
+		require.EqualError(t, gotErr, "403 Forbidden (empty response body)")
+		assert.Equal(t, []Tag(nil), got)
+	})
+
+	t.Run("empty workspace id", func(t *testing.T) {
+		_, gotErr := NewClient("token").Tags("")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
+
+func TestClockify_Clients(t *testing.T) {
+	t.Run("the clients of the workspace are returned", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/clients", r.URL.Path)
+			_, _ = w.Write([]byte(`[{"id":"client-1-uid","name":"Jetstack","workspaceId":"workspace-1-uid","archived":true,"note":""}]`))
+		})
+
+		got, gotErr := clockify.Clients("workspace-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, []Client{{ID: "client-1-uid", Name: "Jetstack", WorkspaceID: "workspace-1-uid", Archived: true}}, got)
+	})
+
+	t.Run("empty workspace id", func(t *testing.T) {
+		_, gotErr := NewClient("token").Clients("")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
+
+func TestClockify_CustomFields(t *testing.T) {
+	t.Run("the custom fields of the workspace are returned", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/custom-fields", r.URL.Path)
+			_, _ = w.Write([]byte(`[{"id":"field-1-uid","name":"ticket","type":"TXT","description":"","placeholder":"PROJ-123","allowedValues":[],"status":"VISIBLE","workspaceId":"workspace-1-uid"},{"id":"field-2-uid","name":"phase","type":"DROPDOWN_SINGLE","allowedValues":["design","build"],"status":"VISIBLE","workspaceId":"workspace-1-uid"}]`))
+		})
+
+		got, gotErr := clockify.CustomFields("workspace-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, []CustomField{
+			{ID: "field-1-uid", Name: "ticket", Type: "TXT", Placeholder: "PROJ-123", AllowedValues: []string{}, Status: "VISIBLE", WorkspaceID: "workspace-1-uid"},
+			{ID: "field-2-uid", Name: "phase", Type: "DROPDOWN_SINGLE", AllowedValues: []string{"design", "build"}, Status: "VISIBLE", WorkspaceID: "workspace-1-uid"},
+		}, got)
+	})
+
+	t.Run("empty workspace id", func(t *testing.T) {
+		_, gotErr := NewClient("token").CustomFields("")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
+
+func TestClockify_TimeEntry(t *testing.T) {
+	t.Run("the time entry is returned", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/time-entries/entry-1-uid", r.URL.Path)
+			_, _ = w.Write([]byte(`{"id":"entry-1-uid","description":"reviewing PR 3574","tagIds":["tag-1-uid"],"billable":true,"projectId":"project-1-uid","timeInterval":{"start":"2021-07-05T09:00:00Z","end":"2021-07-05T10:00:00Z","duration":"PT1H"},"workspaceId":"workspace-1-uid","customFieldValues":[{"customFieldId":"field-1-uid","timeEntryId":"entry-1-uid","value":"PROJ-123","name":"ticket","type":"TXT"},{"customFieldId":"field-3-uid","timeEntryId":"entry-1-uid","value":2.5,"name":"points","type":"NUMBER"}]}`))
+		})
+
+		got, gotErr := clockify.TimeEntry("workspace-1-uid", "entry-1-uid")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, TimeEntry{
+			ID:           "entry-1-uid",
+			Description:  "reviewing PR 3574",
+			TagIds:       []string{"tag-1-uid"},
+			Billable:     true,
+			ProjectID:    "project-1-uid",
+			TimeInterval: TimeInterval{Start: mustParse("2021-07-05 09:00:00"), End: mustParse("2021-07-05 10:00:00"), Duration: "PT1H"},
+			WorkspaceID:  "workspace-1-uid",
+			CustomFieldValues: []CustomFieldValue{
+				{CustomFieldID: "field-1-uid", TimeEntryID: "entry-1-uid", Value: "PROJ-123", Name: "ticket", Type: "TXT"},
+				{CustomFieldID: "field-3-uid", TimeEntryID: "entry-1-uid", Value: 2.5, Name: "points", Type: "NUMBER"},
+			},
+		}, got)
+	})
+
+	t.Run("empty ids", func(t *testing.T) {
+		_, gotErr := NewClient("token").TimeEntry("", "entry-1-uid")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+
+		_, gotErr = NewClient("token").TimeEntry("workspace-1-uid", "")
+		require.Equal(t, ErrEmptyTimeEntryID, gotErr)
+	})
+}
+
+func TestClockify_UpdateTimeEntry(t *testing.T) {
+	t.Run("the whole time entry is sent", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/time-entries/entry-1-uid", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"start":"2021-07-05T09:00:00Z","end":"2021-07-05T10:00:00Z","billable":true,"description":"reviewing the ACME PR","projectId":"project-1-uid","tagIds":["tag-1-uid"]}`, string(body))
+			_, _ = w.Write([]byte(`{"id":"entry-1-uid","description":"reviewing the ACME PR"}`))
+		})
+
+		update := TimeEntry{
+			ID:           "entry-1-uid",
+			Description:  "reviewing PR 3574",
+			TagIds:       []string{"tag-1-uid"},
+			Billable:     true,
+			ProjectID:    "project-1-uid",
+			TimeInterval: TimeInterval{Start: mustParse("2021-07-05 09:00:00"), End: mustParse("2021-07-05 10:00:00"), Duration: "PT1H"},
+		}.UpdateRequest()
+		update.Description = "reviewing the ACME PR"
+
+		got, gotErr := clockify.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", update)
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, TimeEntry{ID: "entry-1-uid", Description: "reviewing the ACME PR"}, got)
+	})
+
+	t.Run("the end is left out when the timer is running", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"start":"2021-07-05T09:00:00Z","billable":false,"description":"triaging","tagIds":null}`, string(body))
+			_, _ = w.Write([]byte(`{"id":"entry-1-uid"}`))
+		})
+
+		_, gotErr := clockify.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", TimeEntry{
+			Description:  "triaging",
+			TimeInterval: TimeInterval{Start: mustParse("2021-07-05 09:00:00")},
+		}.UpdateRequest())
+		require.NoError(t, gotErr)
+	})
+
+	t.Run("the custom fields are kept", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"start":"2021-07-05T09:00:00Z","billable":false,"description":"triaging","tagIds":null,"customFields":[{"customFieldId":"field-1-uid","value":"PROJ-123"}]}`, string(body))
+			_, _ = w.Write([]byte(`{"id":"entry-1-uid"}`))
+		})
+
+		_, gotErr := clockify.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", TimeEntry{
+			Description:       "triaging",
+			TimeInterval:      TimeInterval{Start: mustParse("2021-07-05 09:00:00")},
+			CustomFieldValues: []CustomFieldValue{{CustomFieldID: "field-1-uid", TimeEntryID: "entry-1-uid", Value: "PROJ-123", Name: "ticket", Type: "TXT"}},
+		}.UpdateRequest())
+		require.NoError(t, gotErr)
+	})
+
+	t.Run("the time entry is locked", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"message":"Time entry is locked","code":501}`))
+		})
+
+		_, gotErr := clockify.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", UpdateTimeEntryRequest{})
+		require.EqualError(t, gotErr, "400 Bad Request: Time entry is locked")
+	})
+}
+
+func TestClockify_CreateTimeEntry(t *testing.T) {
+	t.Run("the time entry is created", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/time-entries", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"start":"2021-07-05T09:30:00Z","end":"2021-07-05T11:00:00Z","billable":true,"description":"reviewing PR 3574","projectId":"project-1-uid","taskId":"task-1-uid"}`, string(body))
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id":"entry-1-uid","description":"reviewing PR 3574"}`))
+		})
+
+		end := mustParse("2021-07-05 11:00:00")
+		got, gotErr := clockify.CreateTimeEntry("workspace-1-uid", CreateTimeEntryRequest{
+			Start:       mustParse("2021-07-05 09:30:00"),
+			End:         &end,
+			Billable:    true,
+			Description: "reviewing PR 3574",
+			ProjectID:   "project-1-uid",
+			TaskID:      "task-1-uid",
+		})
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, TimeEntry{ID: "entry-1-uid", Description: "reviewing PR 3574"}, got)
+	})
+
+	t.Run("no workspace ID", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("no request was expected")
+		})
+
+		_, gotErr := clockify.CreateTimeEntry("", CreateTimeEntryRequest{})
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
+
+func TestClockify_DeleteTimeEntry(t *testing.T) {
+	t.Run("the time entry is deleted", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "DELETE", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/time-entries/entry-1-uid", r.URL.Path)
+			w.WriteHeader(204)
+		})
+
+		gotErr := clockify.DeleteTimeEntry("workspace-1-uid", "entry-1-uid")
+		require.NoError(t, gotErr)
+	})
+
+	t.Run("the time entry is locked", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"message":"Time entry is locked","code":501}`))
+		})
+
+		gotErr := clockify.DeleteTimeEntry("workspace-1-uid", "entry-1-uid")
+		require.EqualError(t, gotErr, "400 Bad Request: Time entry is locked")
+	})
+
+	t.Run("no time entry ID", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Error("no request was expected")
+		})
+
+		gotErr := clockify.DeleteTimeEntry("workspace-1-uid", "")
+		require.Equal(t, ErrEmptyTimeEntryID, gotErr)
+	})
+}
+
+func TestClockify_CreateProject(t *testing.T) {
+	t.Run("the project is created", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/projects", r.URL.Path)
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"name":"oss/cert-manager"}`, string(body))
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id":"project-1-uid","name":"oss/cert-manager","workspaceId":"workspace-1-uid"}`))
+		})
+
+		got, gotErr := clockify.CreateProject("workspace-1-uid", "oss/cert-manager")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, Project{ID: "project-1-uid", Name: "oss/cert-manager", WorkspaceID: "workspace-1-uid"}, got)
+	})
+
+	t.Run("the project already exists", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(400)
+			_, _ = w.Write([]byte(`{"message":"Project with name 'oss/cert-manager' already exists","code":501}`))
+		})
+
+		_, gotErr := clockify.CreateProject("workspace-1-uid", "oss/cert-manager")
+		require.EqualError(t, gotErr, "400 Bad Request: Project with name 'oss/cert-manager' already exists")
+	})
+
+	t.Run("no workspace ID", func(t *testing.T) {
+		_, gotErr := NewClient("token").CreateProject("", "oss/cert-manager")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
+
+func TestClockify_CreateTask(t *testing.T) {
+	t.Run("the task is created", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/projects/project-1-uid/tasks", r.URL.Path)
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"name":"1.2"}`, string(body))
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id":"task-1-uid","name":"1.2","projectId":"project-1-uid"}`))
+		})
+
+		got, gotErr := clockify.CreateTask("workspace-1-uid", "project-1-uid", "1.2")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, Task{ID: "task-1-uid", Name: "1.2", ProjectID: "project-1-uid"}, got)
+	})
+
+	t.Run("no project ID", func(t *testing.T) {
+		_, gotErr := NewClient("token").CreateTask("workspace-1-uid", "", "1.2")
+		require.Equal(t, ErrEmptyProjectID, gotErr)
+	})
+}
+
+func TestClockify_CreateTag(t *testing.T) {
+	t.Run("the tag is created", func(t *testing.T) {
+		clockify := withFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/api/v1/workspaces/workspace-1-uid/tags", r.URL.Path)
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"name":"meeting"}`, string(body))
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id":"tag-1-uid","name":"meeting","workspaceId":"workspace-1-uid"}`))
+		})
+
+		got, gotErr := clockify.CreateTag("workspace-1-uid", "meeting")
+
+		require.NoError(t, gotErr)
+		assert.Equal(t, Tag{ID: "tag-1-uid", Name: "meeting", WorkspaceID: "workspace-1-uid"}, got)
+	})
+
+	t.Run("no workspace ID", func(t *testing.T) {
+		_, gotErr := NewClient("token").CreateTag("", "meeting")
+		require.Equal(t, ErrEmptyWorkspaceID, gotErr)
+	})
+}
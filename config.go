@@ -33,6 +33,238 @@ func saveConfig(pathRelativeToHome string, c Config) error {
 type Config struct {
 	Token     string `yaml:"token"`
 	Workspace string `yaml:"workspace,omitempty"`
+
+	// TargetHours maps a weekday (e.g. "monday") to the number of hours you
+	// are expected to log on that day. Weekdays that are not listed have a
+	// target of zero hours.
+	TargetHours map[string]float64 `yaml:"target-hours,omitempty"`
+
+	// Holidays are the days, of the form "2021-12-31", on which you are not
+	// expected to log any time.
+	Holidays []string `yaml:"holidays,omitempty"`
+
+	// WorkingHours is used by 'clockidup gaps' to know when you are
+	// supposed to be tracking time. Defaults to 09:00 to 18:00.
+	WorkingHours WorkingHours `yaml:"working-hours,omitempty"`
+
+	// GapThreshold is the duration (e.g. "15m") above which an untracked
+	// period is reported by 'clockidup gaps'. Defaults to 15m.
+	GapThreshold string `yaml:"gap-threshold,omitempty"`
+
+	Lint Lint `yaml:"lint,omitempty"`
+
+	Post Post `yaml:"post,omitempty"`
+
+	Journal Journal `yaml:"journal,omitempty"`
+
+	Standup Standup `yaml:"standup,omitempty"`
+
+	// Rewrite rules are applied in order to the descriptions before the
+	// entries are merged.
+	Rewrite []RewriteRule `yaml:"rewrite,omitempty"`
+
+	// ProjectAliases maps the Clockify project names to the names shown in
+	// the standup, e.g. "prod/cert-manager: cert-manager".
+	ProjectAliases map[string]string `yaml:"project-aliases,omitempty"`
+
+	// The entries of the HideProjects (Clockify names) are left out of the
+	// standup.
+	HideProjects []string `yaml:"hide-projects,omitempty"`
+
+	Links Links `yaml:"links,omitempty"`
+
+	Invoice Invoice `yaml:"invoice,omitempty"`
+
+	Budgets Budgets `yaml:"budgets,omitempty"`
+
+	// Rules set the billable flag and add tags to the entries that match.
+	// They are applied in order, so a later rule wins over an earlier one.
+	Rules []Rule `yaml:"rules,omitempty"`
+}
+
+// Rule sets the billable flag or adds tags to the time entries that match
+// all of its conditions. Project, client and description are regular
+// expressions, and tag is the name of a tag that the entry must have. For
+// example, to make the entries of the ACME projects billable except for the
+// meetings:
+//
+//  - name: acme
+//    client: ^ACME$
+//    set-billable: true
+//  - name: acme-meetings
+//    client: ^ACME$
+//    description: (?i)\bmeeting\b
+//    set-billable: false
+//    add-tags: [meeting]
+//
+// The rules apply to --billable without changing anything in Clockify; use
+// 'clockidup apply-rules' to write them back.
+type Rule struct {
+	Name        string `yaml:"name,omitempty"`
+	Project     string `yaml:"project,omitempty"`
+	Client      string `yaml:"client,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Tag         string `yaml:"tag,omitempty"`
+
+	SetBillable *bool    `yaml:"set-billable,omitempty"`
+	AddTags     []string `yaml:"add-tags,omitempty"`
+}
+
+// Budgets configures the warnings shown under the standup when a project
+// gets close to its time estimate or budget in Clockify.
+type Budgets struct {
+	// Thresholds are in percent. Defaults to 80 and 100.
+	Thresholds []float64 `yaml:"thresholds,omitempty"`
+
+	// Disabled turns the warnings off.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// Invoice configures 'clockidup invoice'. For example:
+//
+//  tax-rate: 20
+//  number-format: ACME-%03d
+//  issuer: |
+//    Jane Doe
+//    1 Main Street, Springfield
+type Invoice struct {
+	// TaxRate is in percent, e.g. 20 for a 20% VAT. Defaults to 0.
+	TaxRate float64 `yaml:"tax-rate,omitempty"`
+
+	// Issuer is shown in the "From" part of the invoice, one line per
+	// line.
+	Issuer string `yaml:"issuer,omitempty"`
+
+	// NumberFormat turns the invoice number into e.g. "INV-0007".
+	// Defaults to "INV-%04d".
+	NumberFormat string `yaml:"number-format,omitempty"`
+
+	// Template and HTMLTemplate are the paths to the Go templates used
+	// instead of the default ones, e.g. "~/invoices/template.html".
+	Template     string `yaml:"template,omitempty"`
+	HTMLTemplate string `yaml:"html-template,omitempty"`
+}
+
+// Links configures how the issue and pull request references found in the
+// descriptions are turned into links. For example:
+//
+//  format: markdown
+//  projects:
+//    prod/cert-manager: github.com/cert-manager/cert-manager
+//  jira:
+//    - match: '\bPROJ-\d+\b'
+//      url: https://example.atlassian.net/browse/
+type Links struct {
+	// Format is one of "none" (default), "markdown" or "slack". It can be
+	// overridden with --links.
+	Format string `yaml:"format,omitempty"`
+
+	// Projects maps the project names to their repository. The
+	// references such as "#3444" found in the entries of the project
+	// become links to the issue or pull request.
+	Projects map[string]string `yaml:"projects,omitempty"`
+
+	Jira []JiraLink `yaml:"jira,omitempty"`
+}
+
+// JiraLink turns what matches into a link made of the URL followed by the
+// matched text, e.g. "https://example.atlassian.net/browse/PROJ-123".
+type JiraLink struct {
+	Match string `yaml:"match"`
+	URL   string `yaml:"url"`
+}
+
+// RewriteRule replaces what matches the regular expression in the
+// descriptions. The replacement can refer to the groups using $1, $2, and so
+// on. For example, to hide an internal code name:
+//
+//  match: '(?i)\bproject falcon\b'
+//  replace: the new API
+type RewriteRule struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+// Standup configures 'clockidup standup'.
+type Standup struct {
+	// Template is a Go template given .Yesterday and .Today (each with a
+	// .Title, .Lines and .Field, see section.Field) as well as .Blockers.
+	// See defaultStandupTemplate.
+	Template string `yaml:"template,omitempty"`
+
+	// The entries tagged with BlockerTag show up under "Blockers".
+	// Defaults to "blocker".
+	BlockerTag string `yaml:"blocker-tag,omitempty"`
+}
+
+// Journal configures 'clockidup journal', which writes the standup to a
+// Markdown note, e.g. an Obsidian or Logseq daily note.
+type Journal struct {
+	// Path is a template, e.g. "~/notes/{{.Date}}.md". See journalPath.
+	Path string `yaml:"path,omitempty"`
+
+	// Heading under which the standup is written. Defaults to "## Standup".
+	Heading string `yaml:"heading,omitempty"`
+}
+
+// Post configures where 'clockidup post' sends the standup. Each sink is
+// optional; the standup is posted to all the configured sinks.
+type Post struct {
+	// Format is either "code" (the standup is put in a code block) or
+	// "list" (each entry is a bullet point). Defaults to "code".
+	Format string `yaml:"format,omitempty"`
+
+	Slack      *Slack      `yaml:"slack,omitempty"`
+	Mattermost *Mattermost `yaml:"mattermost,omitempty"`
+	Teams      *Teams      `yaml:"teams,omitempty"`
+}
+
+// Slack can either use an incoming webhook, or a token (e.g. "xoxp-...")
+// along with a channel name.
+type Slack struct {
+	WebhookURL string `yaml:"webhook-url,omitempty"`
+	Token      string `yaml:"token,omitempty"`
+	Channel    string `yaml:"channel,omitempty"`
+}
+
+type Mattermost struct {
+	WebhookURL string `yaml:"webhook-url,omitempty"`
+	Channel    string `yaml:"channel,omitempty"`
+}
+
+type Teams struct {
+	WebhookURL string `yaml:"webhook-url,omitempty"`
+}
+
+// Lint configures the checks done by 'clockidup lint'.
+type Lint struct {
+	// MaxDuration (e.g. "8h") is the duration above which a single time
+	// entry is reported. Defaults to 8h.
+	MaxDuration string `yaml:"max-duration,omitempty"`
+
+	Rules []LintRule `yaml:"rules,omitempty"`
+}
+
+// LintRule is a team rule that applies to the description of time
+// entries. For example, to require a Jira ticket in the descriptions of the
+// entries of the projects starting with "prod/":
+//
+//  name: jira-ticket
+//  project: ^prod/
+//  must-match: '[A-Z]+-[0-9]+'
+//  message: the description must mention a Jira ticket
+type LintRule struct {
+	Name         string `yaml:"name,omitempty"`
+	Project      string `yaml:"project,omitempty"`
+	MustMatch    string `yaml:"must-match,omitempty"`
+	MustNotMatch string `yaml:"must-not-match,omitempty"`
+	Message      string `yaml:"message,omitempty"`
+}
+
+// WorkingHours are of the form "09:00" and "18:00".
+type WorkingHours struct {
+	Start string `yaml:"start,omitempty"`
+	End   string `yaml:"end,omitempty"`
 }
 
 func loadConfig(pathRelativeToHome string) (Config, error) {
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// reword is a time entry whose description was changed while editing the
+// standup.
+type reword struct {
+	Entry       timeEntry
+	Description string // The new description.
+}
+
+// reviewMessage lets the user edit the standup before it is printed, posted
+// or written to the journal. When $EDITOR is set, the standup is opened in
+// the editor; otherwise, the user is prompted for the lines to keep and the
+// lines to reword. When descriptions were reworded, the user is offered to
+// also update the time entries in Clockify.
+func reviewMessage(client clockifyClient, workspaceName string, msg message) (message, error) {
+	var edited message
+	var err error
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		edited, err = editWithEditor(editor, msg)
+	} else {
+		edited, err = editWithPrompts(msg)
+	}
+	if err != nil {
+		return message{}, err
+	}
+	edited = withEntries(msg, edited)
+
+	// With --merge task or project, a line is made of entries that have
+	// different descriptions, which means we can't push the rewording.
+	mode, err := mergeMode()
+	if err != nil {
+		return message{}, err
+	}
+	if mode == mergeTask || mode == mergeProject {
+		return edited, nil
+	}
+
+	rewords := rewordsOf(msg, edited)
+	if len(rewords) == 0 {
+		return edited, nil
+	}
+
+	push := false
+	err = survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Also update the description of the %d reworded entries in Clockify?", len(rewords)),
+		Default: false,
+	}, &push)
+	if err != nil {
+		return message{}, err
+	}
+	if !push {
+		return edited, nil
+	}
+
+	err = pushRewords(client, workspaceName, rewords)
+	if err != nil {
+		return message{}, err
+	}
+
+	return edited, nil
+}
+
+// editWithEditor opens the plain text standup in the editor. Like Git, the
+// editor is run using the shell so that $EDITOR can contain arguments, e.g.
+// "code --wait".
+func editWithEditor(editor string, msg message) (message, error) {
+	f, err := ioutil.TempFile("", "clockidup-*.md")
+	if err != nil {
+		return message{}, fmt.Errorf("while creating the file to edit: %s", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(msg.String())
+	if err != nil {
+		return message{}, fmt.Errorf("while writing the file to edit: %s", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return message{}, fmt.Errorf("while writing the file to edit: %s", err)
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return message{}, fmt.Errorf("while running '%s': %s", editor, err)
+	}
+
+	bytes, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return message{}, fmt.Errorf("while reading the edited file: %s", err)
+	}
+	if strings.TrimSpace(string(bytes)) == "" {
+		return message{}, fmt.Errorf("the standup is empty, aborting")
+	}
+
+	return parseMessage(string(bytes)), nil
+}
+
+// editWithPrompts asks the user which lines to keep, and then which lines to
+// reword.
+func editWithPrompts(msg message) (message, error) {
+	// The options must be unique for survey to tell them apart, which is
+	// why the section title is part of the option.
+	var options []string
+	for _, s := range msg.Sections {
+		for _, line := range s.Lines {
+			options = append(options, s.Title+": "+line)
+		}
+	}
+	if len(options) == 0 {
+		return msg, nil
+	}
+
+	var keep []string
+	err := survey.AskOne(&survey.MultiSelect{
+		Message:  "Lines to keep:",
+		Options:  options,
+		Default:  options,
+		PageSize: 15,
+	}, &keep)
+	if err != nil {
+		return message{}, err
+	}
+
+	var toReword []string
+	err = survey.AskOne(&survey.MultiSelect{
+		Message:  "Lines to reword:",
+		Options:  keep,
+		PageSize: 15,
+	}, &toReword)
+	if err != nil {
+		return message{}, err
+	}
+
+	edited := message{Footer: msg.Footer}
+	for _, s := range msg.Sections {
+		editedSection := section{Title: s.Title}
+		for _, line := range s.Lines {
+			option := s.Title + ": " + line
+			if !contains(keep, option) {
+				continue
+			}
+			if contains(toReword, option) {
+				err := survey.AskOne(&survey.Input{Message: s.Title + ":", Default: line}, &line)
+				if err != nil {
+					return message{}, err
+				}
+			}
+			editedSection.Lines = append(editedSection.Lines, line)
+		}
+		edited.Sections = append(edited.Sections, editedSection)
+	}
+
+	return edited, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMessage is the reverse of message.String. A line ending with ":"
+// starts a section, the lines starting with "- " are the section's lines, and
+// the remaining lines are footer lines.
+func parseMessage(text string) message {
+	var msg message
+	var current *section
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case line == "":
+			current = nil
+		case strings.HasPrefix(line, "- "):
+			if current == nil {
+				msg.Sections = append(msg.Sections, section{})
+				current = &msg.Sections[len(msg.Sections)-1]
+			}
+			current.Lines = append(current.Lines, strings.TrimPrefix(line, "- "))
+		case strings.HasSuffix(line, ":") && !strings.Contains(line, " "):
+			msg.Sections = append(msg.Sections, section{Title: strings.TrimSuffix(line, ":")})
+			current = &msg.Sections[len(msg.Sections)-1]
+		default:
+			msg.Footer = append(msg.Footer, line)
+		}
+	}
+	return msg
+}
+
+// section returns the section with the given title. An empty section is
+// returned when there is no such section.
+func (m message) section(title string) section {
+	for _, s := range m.Sections {
+		if s.Title == title {
+			return s
+		}
+	}
+	return section{Title: title}
+}
+
+// rewordsOf finds the entries whose description was changed in the edited
+// message.
+func rewordsOf(orig, edited message) []reword {
+	var rewords []reword
+	for _, origSection := range orig.Sections {
+		editedLines := edited.section(origSection.Title).Lines
+		for j, i := range matchLines(origSection, editedLines) {
+			if i == -1 || editedLines[j] == origSection.Lines[i] {
+				continue
+			}
+			entry := origSection.Entries[i]
+			prefix := linePrefix(entry)
+			descr := strings.TrimSuffix(strings.TrimPrefix(trimClockPrefix(editedLines[j], entry), prefix), moneySuffix(entry))
+			descr = strings.TrimSuffix(descr, " (ongoing)")
+			if descr != "" && descr != entry.Description {
+				rewords = append(rewords, reword{Entry: entry, Description: descr})
+			}
+		}
+	}
+	return rewords
+}
+
+// matchLines returns, for each edited line, the index of the entry of the
+// original section that the line was made from, or -1 when the line was
+// added. An edited line is matched with an entry when it still starts with
+// the same "[1.2] project: " prefix. The lines that are left untouched are
+// matched first so that removing a line does not get mistaken for rewording
+// it.
+func matchLines(orig section, editedLines []string) []int {
+	matches := make([]int, len(editedLines))
+	for j := range matches {
+		matches[j] = -1
+	}
+
+	matched := make([]bool, len(orig.Entries))
+	for i := range orig.Entries {
+		for j, line := range editedLines {
+			if matches[j] == -1 && line == orig.Lines[i] {
+				matches[j], matched[i] = i, true
+				break
+			}
+		}
+	}
+
+	for i, entry := range orig.Entries {
+		if matched[i] {
+			continue
+		}
+		prefix := linePrefix(entry)
+		for j, line := range editedLines {
+			if matches[j] != -1 || !strings.HasPrefix(trimClockPrefix(line, entry), prefix) {
+				continue
+			}
+			matches[j] = i
+			break
+		}
+	}
+	return matches
+}
+
+// With --merge none and --times, the lines start with the start (and end)
+// time of the entry.
+func trimClockPrefix(line string, entry timeEntry) string {
+	line = strings.TrimPrefix(line, clockPrefix(entry, true))
+	return strings.TrimPrefix(line, clockPrefix(entry, false))
+}
+
+// withEntries gives the sections of the edited message the entries their
+// lines were made from, so that the links and the custom fields of a line
+// still come from its own entry once lines were removed or moved around.
+// The lines that were added have no entry.
+func withEntries(orig, edited message) message {
+	for k, s := range edited.Sections {
+		origSection := orig.section(s.Title)
+		if len(origSection.Entries) == 0 {
+			continue
+		}
+		entries := make([]timeEntry, len(s.Lines))
+		for j, i := range matchLines(origSection, s.Lines) {
+			if i != -1 {
+				entries[j] = origSection.Entries[i]
+			}
+		}
+		edited.Sections[k].Entries = entries
+	}
+	return edited
+}
+
+// pushRewords updates the description of the reworded time entries in
+// Clockify. Since the update replaces the whole time entry, each time entry
+// is fetched first.
+func pushRewords(client clockifyClient, workspaceName string, rewords []reword) error {
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rewords {
+		for _, id := range r.Entry.IDs {
+			existing, err := client.TimeEntry(workspace.ID, id)
+			if err != nil {
+				return fmt.Errorf("while fetching the time entry '%s': %s", r.Entry.Description, err)
+			}
+			if existing.IsLocked {
+				return fmt.Errorf("the time entry '%s' is locked and cannot be reworded", r.Entry.Description)
+			}
+
+			update := existing.UpdateRequest()
+			update.Description = r.Description
+			_, err = client.UpdateTimeEntry(workspace.ID, id, update)
+			if err != nil {
+				return fmt.Errorf("while updating the time entry '%s': %s", r.Entry.Description, err)
+			}
+		}
+		logutil.Infof("reworded '%s' to '%s' in Clockify", r.Entry.Description, r.Description)
+	}
+
+	return nil
+}
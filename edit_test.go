@@ -0,0 +1,144 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+var (
+	entryStandup = timeEntry{IDs: []string{"entry-1-uid"}, Project: "prod/cert-manager", Description: "cert-manager standup", Duration: 45 * time.Minute}
+	entryReview  = timeEntry{IDs: []string{"entry-2-uid", "entry-3-uid"}, Project: "prod/cert-manager", Description: "reviewing PR 3574", Duration: 72 * time.Minute}
+	entryEmails  = timeEntry{IDs: []string{"entry-4-uid"}, Description: "emails", Duration: 30 * time.Minute}
+)
+
+func Test_parseMessage(t *testing.T) {
+	got := parseMessage(testMessage.String())
+	assert.Equal(t, message{Sections: []section{{Title: testMessage.Sections[0].Title, Lines: testMessage.Sections[0].Lines}}, Footer: testMessage.Footer}, got)
+}
+
+func Test_rewordsOf(t *testing.T) {
+	orig := message{Sections: []section{newSection("Monday", []timeEntry{entryStandup, entryReview, entryEmails}, lineOptions{})}}
+	require.Equal(t, []string{
+		"[.8] prod/cert-manager: cert-manager standup",
+		"[1.2] prod/cert-manager: reviewing PR 3574",
+		"[.5] no-project: emails",
+	}, orig.Sections[0].Lines)
+
+	tests := []struct {
+		name   string
+		edited []string
+		want   []reword
+	}{
+		{
+			name:   "nothing changed",
+			edited: orig.Sections[0].Lines,
+		},
+		{
+			name:   "lines removed",
+			edited: []string{"[1.2] prod/cert-manager: reviewing PR 3574"},
+		},
+		{
+			name: "line reworded and another line removed",
+			edited: []string{
+				"[1.2] prod/cert-manager: reviewing the ACME PR",
+				"[.5] no-project: emails",
+			},
+			want: []reword{{Entry: entryReview, Description: "reviewing the ACME PR"}},
+		},
+		{
+			name: "project changed in the line, nothing to push",
+			edited: []string{
+				"[.8] prod/cert-manager: cert-manager standup",
+				"[1.2] cert-manager: reviewing the ACME PR",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rewordsOf(orig, message{Sections: []section{{Title: "Monday", Lines: tt.edited}}})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("with --money, the amount is not part of the description", func(t *testing.T) {
+		earning := entryReview
+		earning.Rate, earning.Earned = clockify.HourlyRate{Amount: 10000, Currency: "USD"}, 12000
+		orig := message{Sections: []section{newSection("Monday", []timeEntry{earning}, lineOptions{Money: true})}}
+		require.Equal(t, []string{"[1.2] prod/cert-manager: reviewing PR 3574 (120.00 USD)"}, orig.Sections[0].Lines)
+
+		got := rewordsOf(orig, message{Sections: []section{{Title: "Monday", Lines: []string{"[1.2] prod/cert-manager: reviewing the ACME PR (120.00 USD)"}}}})
+		assert.Equal(t, []reword{{Entry: earning, Description: "reviewing the ACME PR"}}, got)
+	})
+}
+
+func Test_withEntries(t *testing.T) {
+	l, err := newLinker(Config{Links: Links{Projects: map[string]string{
+		"prod/cert-manager": "github.com/cert-manager/cert-manager",
+		"prod/website":      "github.com/cert-manager/website",
+	}}})
+	require.NoError(t, err)
+	website := timeEntry{IDs: []string{"entry-5-uid"}, Project: "prod/website", Description: "fixing #12", Duration: 30 * time.Minute}
+	review := entryReview
+	review.Description = "reviewing #3574"
+	orig := message{Sections: []section{newSection("Monday", []timeEntry{review, entryEmails, website}, lineOptions{})}}
+
+	// The middle line is removed and a line is added.
+	got := withEntries(orig, message{Sections: []section{{Title: "Monday", Lines: []string{
+		"[1.2] prod/cert-manager: reviewing #3574",
+		"[.5] prod/website: fixing #12",
+		"[.5] prod/website: release #13",
+	}}}})
+
+	assert.Equal(t, []timeEntry{review, website, {}}, got.Sections[0].Entries)
+	assert.Equal(t, []string{
+		"[1.2] prod/cert-manager: reviewing [#3574](https://github.com/cert-manager/cert-manager/issues/3574)",
+		"[.5] prod/website: fixing [#12](https://github.com/cert-manager/website/issues/12)",
+		"[.5] prod/website: release #13",
+	}, l.message(got, linksMarkdown).Sections[0].Lines)
+}
+
+func Test_editWithEditor(t *testing.T) {
+	msg := message{Sections: []section{newSection("Monday", []timeEntry{entryStandup, entryReview}, lineOptions{})}}
+
+	// Acts as an editor that rewords the second line and removes the first.
+	editor := `edit() { sed -e "s/PR 3574/the ACME PR/" -e "/standup/d" "$1" > "$1.tmp" && mv "$1.tmp" "$1"; }; edit`
+
+	got, err := editWithEditor(editor, msg)
+	require.NoError(t, err)
+	assert.Equal(t, message{Sections: []section{{Title: "Monday", Lines: []string{"[1.2] prod/cert-manager: reviewing the ACME PR"}}}}, got)
+
+	_, err = editWithEditor(`false`, msg)
+	require.EqualError(t, err, "while running 'false': exit status 1")
+}
+
+func Test_pushRewords(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockclockifyClient(ctrl)
+	m := client.EXPECT()
+
+	m.Workspaces().Return([]clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}, nil).AnyTimes()
+	for _, id := range []string{"entry-2-uid", "entry-3-uid"} {
+		existing := clockify.TimeEntry{ID: id, Description: "reviewing PR 3574", ProjectID: "project-1-uid", Billable: true, TagIds: []string{"tag-1-uid"},
+			TimeInterval: clockify.TimeInterval{Start: mustParse("2021-07-05T09:00:00Z"), End: mustParse("2021-07-05T10:00:00Z")}}
+		m.TimeEntry("workspace-1-uid", id).Return(existing, nil)
+
+		update := existing.UpdateRequest()
+		update.Description = "reviewing the ACME PR"
+		m.UpdateTimeEntry("workspace-1-uid", id, update).Return(clockify.TimeEntry{}, nil)
+	}
+
+	err := pushRewords(client, "workspace-1", []reword{{Entry: entryReview, Description: "reviewing the ACME PR"}})
+	require.NoError(t, err)
+
+	m.TimeEntry("workspace-1-uid", "entry-1-uid").Return(clockify.TimeEntry{ID: "entry-1-uid", IsLocked: true}, nil)
+	err = pushRewords(client, "workspace-1", []reword{{Entry: entryStandup, Description: "standup"}})
+	require.EqualError(t, err, "the time entry 'cert-manager standup' is locked and cannot be reworded")
+}
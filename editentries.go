@@ -0,0 +1,308 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// The actions offered by 'clockidup edit'.
+const (
+	actionReword = "reword"
+	actionMove   = "change project/task"
+	actionTimes  = "adjust start/end"
+	actionSplit  = "split"
+	actionDelete = "delete"
+)
+
+// entryEdit is what the user wants to do with a time entry.
+type entryEdit struct {
+	Action string
+
+	Description string // For actionReword.
+	Project     string // For actionMove. Empty means no project.
+	Task        string // For actionMove. Empty means no task.
+	Start, End  string // For actionTimes, e.g. "9:30". Empty means unchanged.
+	At          string // For actionSplit, e.g. "10:15".
+}
+
+// splitEntry returns the update that ends the entry at 'at' and the request
+// that creates the second half, which starts at 'at'. The second half has
+// the same description, project, task, tags and custom fields.
+func splitEntry(existing clockify.TimeEntry, at time.Time) (clockify.UpdateTimeEntryRequest, clockify.CreateTimeEntryRequest, error) {
+	start, end := existing.TimeInterval.Start, existing.TimeInterval.End
+	if end.IsZero() {
+		return clockify.UpdateTimeEntryRequest{}, clockify.CreateTimeEntryRequest{}, fmt.Errorf("the time entry '%s' is still running, stop the timer before splitting it", existing.Description)
+	}
+	if !at.After(start) || !at.Before(end) {
+		return clockify.UpdateTimeEntryRequest{}, clockify.CreateTimeEntryRequest{}, fmt.Errorf("%s is not between the start (%s) and the end (%s) of the time entry", at.Local().Format("15:04"), start.Local().Format("15:04"), end.Local().Format("15:04"))
+	}
+
+	at = at.UTC()
+	first := existing.UpdateRequest()
+	first.End = &at
+
+	second := clockify.CreateTimeEntryRequest{
+		Start:        at,
+		End:          &end,
+		Billable:     existing.Billable,
+		Description:  existing.Description,
+		ProjectID:    existing.ProjectID,
+		TaskID:       existing.TaskID,
+		TagIds:       existing.TagIds,
+		CustomFields: existing.UpdateRequest().CustomFields,
+	}
+	return first, second, nil
+}
+
+// applyEdit changes the time entry in Clockify. The locked entries, e.g. the
+// ones that were approved or that are before the lock date of the
+// workspace, are left untouched.
+func applyEdit(client clockifyClient, workspace clockify.Workspace, existing clockify.TimeEntry, edit entryEdit) error {
+	if existing.IsLocked {
+		return fmt.Errorf("the time entry '%s' is locked and cannot be edited", existing.Description)
+	}
+	day := existing.TimeInterval.Start.Local()
+
+	update := existing.UpdateRequest()
+	switch edit.Action {
+	case actionReword:
+		if strings.TrimSpace(edit.Description) == "" {
+			return fmt.Errorf("the description can't be empty")
+		}
+		update.Description = strings.TrimSpace(edit.Description)
+	case actionMove:
+		update.ProjectID, update.TaskID = "", ""
+		if edit.Project != "" {
+			project, err := resolveProject(client, workspace.ID, edit.Project)
+			if err != nil {
+				return err
+			}
+			update.ProjectID = project.ID
+		}
+		if edit.Task != "" {
+			if update.ProjectID == "" {
+				return fmt.Errorf("the task '%s' needs a project", edit.Task)
+			}
+			task, err := resolveTask(client, workspace.ID, update.ProjectID, edit.Task)
+			if err != nil {
+				return err
+			}
+			update.TaskID = task.ID
+		}
+	case actionTimes:
+		if edit.Start != "" {
+			start, err := parseTimeOn(day, edit.Start)
+			if err != nil {
+				return fmt.Errorf("start: %s", err)
+			}
+			update.Start = start.UTC()
+		}
+		if edit.End != "" {
+			end, err := parseTimeOn(day, edit.End)
+			if err != nil {
+				return fmt.Errorf("end: %s", err)
+			}
+			end = end.UTC()
+			update.End = &end
+		}
+		if update.End != nil && !update.End.After(update.Start) {
+			return fmt.Errorf("the time entry would end (%s) before it starts (%s)", update.End.Local().Format("15:04"), update.Start.Local().Format("15:04"))
+		}
+	case actionSplit:
+		at, err := parseTimeOn(day, edit.At)
+		if err != nil {
+			return err
+		}
+		first, second, err := splitEntry(existing, at)
+		if err != nil {
+			return err
+		}
+		_, err = client.UpdateTimeEntry(workspace.ID, existing.ID, first)
+		if err != nil {
+			return fmt.Errorf("while updating the time entry '%s': %s", existing.Description, err)
+		}
+		_, err = client.CreateTimeEntry(workspace.ID, second)
+		if err != nil {
+			return fmt.Errorf("while creating the second half of the time entry '%s': %s", existing.Description, err)
+		}
+		return nil
+	case actionDelete:
+		err := client.DeleteTimeEntry(workspace.ID, existing.ID)
+		if err != nil {
+			return fmt.Errorf("while deleting the time entry '%s': %s", existing.Description, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("programmer mistake: unknown action '%s'", edit.Action)
+	}
+
+	_, err := client.UpdateTimeEntry(workspace.ID, existing.ID, update)
+	if err != nil {
+		return fmt.Errorf("while updating the time entry '%s': %s", existing.Description, err)
+	}
+	return nil
+}
+
+// resolveProject finds the non-archived project that the user meant, see
+// resolveName.
+func resolveProject(client clockifyClient, workspaceID, query string) (clockify.Project, error) {
+	projects, err := client.Projects(workspaceID)
+	if err != nil {
+		return clockify.Project{}, fmt.Errorf("while fetching projects: %s", err)
+	}
+	var names []string
+	for _, p := range projects {
+		if !p.Archived {
+			names = append(names, p.Name)
+		}
+	}
+	name, err := resolveName("project", names, query)
+	if err != nil {
+		return clockify.Project{}, err
+	}
+	for _, p := range projects {
+		if p.Name == name && !p.Archived {
+			return p, nil
+		}
+	}
+	return clockify.Project{}, fmt.Errorf("programmer mistake: the project '%s' was supposed to exist", name)
+}
+
+// resolveTask finds the task of the project that the user meant, see
+// resolveName.
+func resolveTask(client clockifyClient, workspaceID, projectID, query string) (clockify.Task, error) {
+	tasks, err := client.Tasks(workspaceID, projectID)
+	if err != nil {
+		return clockify.Task{}, fmt.Errorf("while fetching tasks: %s", err)
+	}
+	var names []string
+	for _, t := range tasks {
+		names = append(names, t.Name)
+	}
+	name, err := resolveName("task", names, query)
+	if err != nil {
+		return clockify.Task{}, err
+	}
+	for _, t := range tasks {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return clockify.Task{}, fmt.Errorf("programmer mistake: the task '%s' was supposed to exist", name)
+}
+
+// promptEdit asks what to do with the time entry.
+func promptEdit(entry timeEntry) (entryEdit, error) {
+	edit := entryEdit{}
+	err := survey.AskOne(&survey.Select{
+		Message: "What do you want to do?",
+		Options: []string{actionReword, actionMove, actionTimes, actionSplit, actionDelete},
+	}, &edit.Action)
+	if err != nil {
+		return entryEdit{}, err
+	}
+
+	const clock = "15:04"
+	switch edit.Action {
+	case actionReword:
+		err = survey.AskOne(&survey.Input{Message: "Description:", Default: entry.Description}, &edit.Description)
+	case actionMove:
+		err = survey.Ask([]*survey.Question{
+			{Name: "project", Prompt: &survey.Input{Message: "Project (empty for none):", Default: entry.Project}},
+			{Name: "task", Prompt: &survey.Input{Message: "Task (empty for none):", Default: entry.Task}},
+		}, &edit)
+	case actionTimes:
+		end := ""
+		if !entry.End.IsZero() {
+			end = entry.End.Local().Format(clock)
+		}
+		err = survey.Ask([]*survey.Question{
+			{Name: "start", Prompt: &survey.Input{Message: "Start:", Default: entry.Start.Local().Format(clock)}},
+			{Name: "end", Prompt: &survey.Input{Message: "End:", Default: end}},
+		}, &edit)
+	case actionSplit:
+		middle := entry.Start.Add(entry.End.Sub(entry.Start) / 2)
+		err = survey.AskOne(&survey.Input{Message: "Split at:", Default: middle.Local().Format(clock)}, &edit.At, survey.WithValidator(survey.Required))
+	case actionDelete:
+		confirmed := false
+		err = survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Delete '%s'?", entry.Description)}, &confirmed)
+		if err == nil && !confirmed {
+			return entryEdit{}, fmt.Errorf("the time entry was not deleted")
+		}
+	}
+	if err != nil {
+		return entryEdit{}, err
+	}
+	return edit, nil
+}
+
+func runEditEntries(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	day := time.Now()
+	if flags.NArg() > 0 {
+		day, err = parseDay(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, workspace, _, err := fetchTimeEntries(client, time.Now, workspaceName, startOfDay(day), endOfDay(day))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No time entries on %s.\n", day.Format(layoutISO))
+		return nil
+	}
+	entries = sortedByStart(entries)
+
+	// The lines are prefixed with their position since survey needs the
+	// options to be unique.
+	lines := standupLines(entries, lineOptions{Start: true, End: true})
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, lines[i])
+	}
+	var picked int
+	err = survey.AskOne(&survey.Select{
+		Message:  "Time entry to edit:",
+		Options:  lines,
+		PageSize: 15,
+	}, &picked)
+	if err != nil {
+		return err
+	}
+	entry := entries[picked]
+
+	existing, err := client.TimeEntry(workspace.ID, entry.IDs[0])
+	if err != nil {
+		return fmt.Errorf("while fetching the time entry '%s': %s", entry.Description, err)
+	}
+	if existing.IsLocked {
+		return fmt.Errorf("the time entry '%s' is locked and cannot be edited", existing.Description)
+	}
+
+	edit, err := promptEdit(entry)
+	if err != nil {
+		return err
+	}
+
+	err = applyEdit(client, workspace, existing, edit)
+	if err != nil {
+		return err
+	}
+
+	logutil.Infof("%s: done for '%s'", edit.Action, entry.Description)
+	return nil
+}
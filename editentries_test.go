@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+// The times typed by the user are local times.
+func localTime(hour, min int) time.Time {
+	return time.Date(2021, 7, 5, hour, min, 0, 0, time.Local).UTC()
+}
+
+func Test_splitEntry(t *testing.T) {
+	start, end, at := localTime(9, 0), localTime(11, 0), localTime(10, 15)
+	existing := clockify.TimeEntry{
+		ID:                "entry-1-uid",
+		Description:       "reviewing PR 3574",
+		ProjectID:         "project-1-uid",
+		TaskID:            "task-1-uid",
+		TagIds:            []string{"tag-1-uid"},
+		Billable:          true,
+		CustomFieldValues: []clockify.CustomFieldValue{{CustomFieldID: "field-1-uid", Value: "CM-42"}},
+		TimeInterval:      clockify.TimeInterval{Start: start, End: end},
+	}
+
+	t.Run("the second half is a copy of the first", func(t *testing.T) {
+		first, second, err := splitEntry(existing, at)
+		require.NoError(t, err)
+
+		wantFirst := existing.UpdateRequest()
+		wantFirst.End = &at
+		assert.Equal(t, wantFirst, first)
+		assert.Equal(t, clockify.CreateTimeEntryRequest{
+			Start:        at,
+			End:          &end,
+			Billable:     true,
+			Description:  "reviewing PR 3574",
+			ProjectID:    "project-1-uid",
+			TaskID:       "task-1-uid",
+			TagIds:       []string{"tag-1-uid"},
+			CustomFields: []clockify.UpdateCustomField{{CustomFieldID: "field-1-uid", Value: "CM-42"}},
+		}, second)
+	})
+
+	t.Run("outside of the entry", func(t *testing.T) {
+		_, _, err := splitEntry(existing, end)
+		require.EqualError(t, err, "11:00 is not between the start (09:00) and the end (11:00) of the time entry")
+	})
+
+	t.Run("still running", func(t *testing.T) {
+		running := existing
+		running.TimeInterval.End = time.Time{}
+		_, _, err := splitEntry(running, at)
+		require.EqualError(t, err, "the time entry 'reviewing PR 3574' is still running, stop the timer before splitting it")
+	})
+}
+
+func Test_applyEdit(t *testing.T) {
+	workspace := clockify.Workspace{ID: "workspace-1-uid", Name: "workspace-1"}
+	existing := clockify.TimeEntry{
+		ID:           "entry-1-uid",
+		Description:  "reviewing PR 3574",
+		ProjectID:    "project-1-uid",
+		Billable:     true,
+		TimeInterval: clockify.TimeInterval{Start: localTime(9, 0), End: localTime(11, 0)},
+	}
+	updated := func(change func(u *clockify.UpdateTimeEntryRequest)) clockify.UpdateTimeEntryRequest {
+		u := existing.UpdateRequest()
+		change(&u)
+		return u
+	}
+	timeOf := func(hour, min int) *time.Time {
+		t := localTime(hour, min)
+		return &t
+	}
+
+	tests := []struct {
+		name          string
+		givenExisting clockify.TimeEntry
+		givenEdit     entryEdit
+		givenMock     func(m *mocks.MockclockifyClientMockRecorder)
+		wantErr       string
+	}{
+		{
+			name:      "reword",
+			givenEdit: entryEdit{Action: actionReword, Description: " reviewing the ACME PR "},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", updated(func(u *clockify.UpdateTimeEntryRequest) {
+					u.Description = "reviewing the ACME PR"
+				})).Return(clockify.TimeEntry{}, nil)
+			},
+		},
+		{
+			name:      "change project and task",
+			givenEdit: entryEdit{Action: actionMove, Project: "admin", Task: "hiring"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Projects("workspace-1-uid").Return([]clockify.Project{{ID: "project-1-uid", Name: "prod/cert-manager"}, {ID: "project-2-uid", Name: "admin"}}, nil)
+				m.Tasks("workspace-1-uid", "project-2-uid").Return([]clockify.Task{{ID: "task-1-uid", Name: "hiring"}}, nil)
+				m.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", updated(func(u *clockify.UpdateTimeEntryRequest) {
+					u.ProjectID, u.TaskID = "project-2-uid", "task-1-uid"
+				})).Return(clockify.TimeEntry{}, nil)
+			},
+		},
+		{
+			name:      "remove the project",
+			givenEdit: entryEdit{Action: actionMove},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", updated(func(u *clockify.UpdateTimeEntryRequest) {
+					u.ProjectID = ""
+				})).Return(clockify.TimeEntry{}, nil)
+			},
+		},
+		{
+			name:      "adjust the end",
+			givenEdit: entryEdit{Action: actionTimes, End: "11:30"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", updated(func(u *clockify.UpdateTimeEntryRequest) {
+					u.End = timeOf(11, 30)
+				})).Return(clockify.TimeEntry{}, nil)
+			},
+		},
+		{
+			name:      "the start after the end",
+			givenEdit: entryEdit{Action: actionTimes, Start: "12:00"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {},
+			wantErr:   "the time entry would end (11:00) before it starts (12:00)",
+		},
+		{
+			name:      "split",
+			givenEdit: entryEdit{Action: actionSplit, At: "10:15"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.UpdateTimeEntry("workspace-1-uid", "entry-1-uid", updated(func(u *clockify.UpdateTimeEntryRequest) {
+					u.End = timeOf(10, 15)
+				})).Return(clockify.TimeEntry{}, nil)
+				m.CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{
+					Start:       localTime(10, 15),
+					End:         timeOf(11, 0),
+					Billable:    true,
+					Description: "reviewing PR 3574",
+					ProjectID:   "project-1-uid",
+				}).Return(clockify.TimeEntry{ID: "entry-2-uid"}, nil)
+			},
+		},
+		{
+			name:      "delete",
+			givenEdit: entryEdit{Action: actionDelete},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.DeleteTimeEntry("workspace-1-uid", "entry-1-uid").Return(nil)
+			},
+		},
+		{
+			name: "locked entries are left untouched",
+			givenExisting: func() clockify.TimeEntry {
+				locked := existing
+				locked.IsLocked = true
+				return locked
+			}(),
+			givenEdit: entryEdit{Action: actionDelete},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {},
+			wantErr:   "the time entry 'reviewing PR 3574' is locked and cannot be edited",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mocks.NewMockclockifyClient(ctrl)
+			tt.givenMock(client.EXPECT())
+
+			if tt.givenExisting.ID == "" {
+				tt.givenExisting = existing
+			}
+			err := applyEdit(client, workspace, tt.givenExisting, tt.givenEdit)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatCustomValue turns the value of a custom field into text. Numbers are
+// shown without trailing zeros, checkboxes as "true" or "false", and the
+// values of the multiple-choice dropdowns are separated by commas.
+func formatCustomValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, formatCustomValue(item))
+		}
+		return strings.Join(values, ", ")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// customFieldNames returns the names of the custom fields used by at least
+// one of the entries, sorted alphabetically.
+func customFieldNames(entries []timeEntry) []string {
+	var names []string
+	for _, entry := range entries {
+		for name := range entry.CustomFields {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// The columns of 'clockidup export', followed by one column per custom
+// field.
+var exportColumns = []string{"date", "start", "end", "hours", "project", "task", "description", "billable", "tags"}
+
+// writeCSV writes one row per entry, in chronological order. The date and
+// times are local. The end of the entries still going on is empty.
+func writeCSV(w io.Writer, entries []timeEntry, fields []string) error {
+	out := csv.NewWriter(w)
+	err := out.Write(append(append([]string{}, exportColumns...), fields...))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range sortedByStart(entries) {
+		start := entry.Start.Local()
+		end := ""
+		if !entry.End.IsZero() {
+			end = entry.End.Local().Format("15:04")
+		}
+		row := []string{
+			start.Format(layoutISO),
+			start.Format("15:04"),
+			end,
+			strconv.FormatFloat(entry.Duration.Hours(), 'f', 2, 64),
+			entry.Project,
+			entry.Task,
+			entry.Description,
+			strconv.FormatBool(entry.Billable),
+			strings.Join(entry.Tags, ", "),
+		}
+		for _, field := range fields {
+			row = append(row, entry.CustomFields[field])
+		}
+		err = out.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func runExport(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	fieldsFlag := flags.String("fields", "", "Comma-separated custom fields to export, e.g. 'ticket,phase'. Defaults to all the custom fields used by the entries.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required, e.g. 'clockidup export --from 2021-07-01 --to 2021-07-31'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+
+	entries, err := timeEntriesForRange(client, time.Now, workspaceName, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+	entries, err = billableOnly(conf, entries)
+	if err != nil {
+		return err
+	}
+
+	fields := customFieldNames(entries)
+	if *fieldsFlag != "" {
+		fields = nil
+		for _, field := range strings.Split(*fieldsFlag, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return writeCSV(os.Stdout, entries, fields)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_formatCustomValue(t *testing.T) {
+	tests := []struct {
+		given interface{}
+		want  string
+	}{
+		{given: "CM-42", want: "CM-42"},
+		{given: 3.0, want: "3"},
+		{given: 3.25, want: "3.25"},
+		{given: true, want: "true"},
+		{given: []interface{}{"review", "testing"}, want: "review, testing"},
+		{given: nil, want: ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatCustomValue(tt.given))
+	}
+}
+
+func Test_customFieldNames(t *testing.T) {
+	got := customFieldNames([]timeEntry{
+		{CustomFields: map[string]string{"ticket": "CM-42", "phase": "review"}},
+		{},
+		{CustomFields: map[string]string{"ticket": "CM-43", "client-ref": "PO-1"}},
+	})
+	assert.Equal(t, []string{"client-ref", "phase", "ticket"}, got)
+}
+
+func Test_writeCSV(t *testing.T) {
+	entries := []timeEntry{
+		{Project: "prod/cert-manager", Description: "triaging #2037, then lunch", Duration: 30 * time.Minute, Billable: true, Start: time.Date(2021, 7, 1, 14, 0, 0, 0, time.Local), CustomFields: map[string]string{"ticket": "CM-2037"}},
+		{Project: "prod/cert-manager", Task: "1.2", Description: "reviewing PR 3574", Duration: 75 * time.Minute, Tags: []string{"review", "oss"}, Start: time.Date(2021, 7, 1, 9, 15, 0, 0, time.Local), End: time.Date(2021, 7, 1, 10, 30, 0, 0, time.Local), CustomFields: map[string]string{"ticket": "CM-42", "phase": "review"}},
+	}
+
+	var b strings.Builder
+	err := writeCSV(&b, entries, []string{"ticket", "phase"})
+	require.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		date,start,end,hours,project,task,description,billable,tags,ticket,phase
+		2021-07-01,09:15,10:30,1.25,prod/cert-manager,1.2,reviewing PR 3574,false,"review, oss",CM-42,review
+		2021-07-01,14:00,,0.50,prod/cert-manager,,"triaging #2037, then lunch",true,,CM-2037,
+	`), b.String())
+}
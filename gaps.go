@@ -0,0 +1,223 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const defaultGapThreshold = 15 * time.Minute
+
+// workingHours returns the start and end of the working hours on the given
+// day. When 'working-hours' is not set in the config, the working hours are
+// 09:00 to 18:00.
+func workingHours(conf Config, day time.Time) (start, end time.Time, _ error) {
+	startStr, endStr := "09:00", "18:00"
+	if conf.WorkingHours.Start != "" {
+		startStr = conf.WorkingHours.Start
+	}
+	if conf.WorkingHours.End != "" {
+		endStr = conf.WorkingHours.End
+	}
+
+	startClock, err := time.Parse("15:04", startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("working-hours: start '%s' is not of the form 09:00", startStr)
+	}
+	endClock, err := time.Parse("15:04", endStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("working-hours: end '%s' is not of the form 18:00", endStr)
+	}
+	if !endClock.After(startClock) {
+		return time.Time{}, time.Time{}, fmt.Errorf("working-hours: end (%s) must be after start (%s)", endStr, startStr)
+	}
+
+	start = time.Date(day.Year(), day.Month(), day.Day(), startClock.Hour(), startClock.Minute(), 0, 0, day.Location())
+	end = time.Date(day.Year(), day.Month(), day.Day(), endClock.Hour(), endClock.Minute(), 0, 0, day.Location())
+	return start, end, nil
+}
+
+// endOrNow returns the end of the entry, or now if the entry is still going
+// on.
+func endOrNow(entry timeEntry, now time.Time) time.Time {
+	if entry.End.IsZero() {
+		return now
+	}
+	return entry.End
+}
+
+// sortedByStart returns a copy of the entries sorted by start time.
+func sortedByStart(entries []timeEntry) []timeEntry {
+	sorted := make([]timeEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	return sorted
+}
+
+type gap struct {
+	Start, End time.Time
+}
+
+// findGaps returns the periods between from and to that are not covered by
+// any time entry and that are longer than the threshold. The entries don't
+// need to be sorted. Entries that are still going on are considered to end
+// now.
+func findGaps(entries []timeEntry, from, to time.Time, threshold time.Duration, now time.Time) []gap {
+	// There can't be any gap in the future.
+	if to.After(now) {
+		to = now
+	}
+
+	var gaps []gap
+	cursor := from
+	for _, entry := range sortedByStart(entries) {
+		if !cursor.Before(to) {
+			break
+		}
+
+		if entry.Start.After(cursor) {
+			gapEnd := entry.Start
+			if gapEnd.After(to) {
+				gapEnd = to
+			}
+			if gapEnd.Sub(cursor) > threshold {
+				gaps = append(gaps, gap{Start: cursor, End: gapEnd})
+			}
+		}
+
+		if end := endOrNow(entry, now); end.After(cursor) {
+			cursor = end
+		}
+	}
+
+	if to.Sub(cursor) > threshold {
+		gaps = append(gaps, gap{Start: cursor, End: to})
+	}
+
+	return gaps
+}
+
+type overlap struct {
+	First, Second timeEntry
+	Start, End    time.Time
+}
+
+// findOverlaps returns the pairs of time entries that overlap. The entries
+// don't need to be sorted.
+func findOverlaps(entries []timeEntry, now time.Time) []overlap {
+	sorted := sortedByStart(entries)
+
+	var overlaps []overlap
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			firstEnd, secondEnd := endOrNow(sorted[i], now), endOrNow(sorted[j], now)
+			if !sorted[j].Start.Before(firstEnd) {
+				break
+			}
+
+			end := firstEnd
+			if secondEnd.Before(end) {
+				end = secondEnd
+			}
+			overlaps = append(overlaps, overlap{
+				First:  sorted[i],
+				Second: sorted[j],
+				Start:  sorted[j].Start,
+				End:    end,
+			})
+		}
+	}
+
+	return overlaps
+}
+
+// formatDuration shows durations the way people write them, e.g. "45m",
+// "2h" or "1h30".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%02d", h, m)
+	}
+}
+
+// describe returns the entry the way it appears in the standup, e.g.
+// "project-1: work on clockidup".
+func describe(entry timeEntry) string {
+	project := entry.Project
+	if project == "" {
+		project = "no-project"
+	}
+	return project + ": " + entry.Description
+}
+
+func runGaps(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("gaps", flag.ContinueOnError)
+	threshold := flags.Duration("threshold", 0, "Only show the gaps longer than this duration. Defaults to 'gap-threshold' in the config, or 15m.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *threshold == 0 {
+		*threshold = defaultGapThreshold
+		if conf.GapThreshold != "" {
+			*threshold, err = time.ParseDuration(conf.GapThreshold)
+			if err != nil {
+				return fmt.Errorf("gap-threshold: %s", err)
+			}
+		}
+	}
+
+	dayArg := "today"
+	if flags.NArg() > 0 {
+		dayArg = flags.Arg(0)
+	}
+	day, err := parseDay(dayArg)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := workingHours(conf, day)
+	if err != nil {
+		return err
+	}
+
+	entries, err := timeEntriesForDay(client, time.Now, workspaceName, day)
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	now := time.Now()
+	gaps := findGaps(entries, from, to, *threshold, now)
+	overlaps := findOverlaps(entries, now)
+
+	const clock = "15:04"
+	if len(gaps) == 0 {
+		fmt.Printf("No gaps longer than %s between %s and %s.\n", formatDuration(*threshold), from.Format(clock), to.Format(clock))
+	} else {
+		fmt.Printf("Gaps longer than %s between %s and %s:\n", formatDuration(*threshold), from.Format(clock), to.Format(clock))
+	}
+	for _, g := range gaps {
+		fmt.Printf("- %s–%s (%s)\n", g.Start.In(day.Location()).Format(clock), g.End.In(day.Location()).Format(clock), formatDuration(g.End.Sub(g.Start)))
+	}
+
+	if len(overlaps) == 0 {
+		fmt.Printf("No overlapping entries.\n")
+	} else {
+		fmt.Printf("Overlapping entries:\n")
+	}
+	for _, o := range overlaps {
+		fmt.Printf("- %s–%s (%s): %q and %q\n", o.Start.In(day.Location()).Format(clock), o.End.In(day.Location()).Format(clock), formatDuration(o.End.Sub(o.Start)), describe(o.First), describe(o.Second))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findGaps(t *testing.T) {
+	from, to := mustParse("2021-07-03T09:00:00Z"), mustParse("2021-07-03T18:00:00Z")
+	tests := []struct {
+		name      string
+		given     []timeEntry
+		givenNow  time.Time
+		threshold time.Duration
+		want      []gap
+	}{
+		{
+			name:      "no entries means the whole day is a gap",
+			givenNow:  mustParse("2021-07-04T00:00:00Z"),
+			threshold: 15 * time.Minute,
+			want:      []gap{{Start: from, End: to}},
+		},
+		{
+			name: "gaps shorter than the threshold are ignored",
+			given: []timeEntry{
+				{Description: "afternoon", Start: mustParse("2021-07-03T13:10:00Z"), End: mustParse("2021-07-03T18:00:00Z")},
+				{Description: "morning", Start: mustParse("2021-07-03T09:00:00Z"), End: mustParse("2021-07-03T12:00:00Z")},
+				{Description: "lunch meeting", Start: mustParse("2021-07-03T12:10:00Z"), End: mustParse("2021-07-03T13:00:00Z")},
+			},
+			givenNow:  mustParse("2021-07-04T00:00:00Z"),
+			threshold: 15 * time.Minute,
+			want:      nil,
+		},
+		{
+			name: "gaps at the beginning, in the middle and at the end",
+			given: []timeEntry{
+				{Description: "late morning", Start: mustParse("2021-07-03T10:00:00Z"), End: mustParse("2021-07-03T12:00:00Z")},
+				{Description: "afternoon", Start: mustParse("2021-07-03T13:30:00Z"), End: mustParse("2021-07-03T17:00:00Z")},
+			},
+			givenNow:  mustParse("2021-07-04T00:00:00Z"),
+			threshold: 15 * time.Minute,
+			want: []gap{
+				{Start: mustParse("2021-07-03T09:00:00Z"), End: mustParse("2021-07-03T10:00:00Z")},
+				{Start: mustParse("2021-07-03T12:00:00Z"), End: mustParse("2021-07-03T13:30:00Z")},
+				{Start: mustParse("2021-07-03T17:00:00Z"), End: mustParse("2021-07-03T18:00:00Z")},
+			},
+		},
+		{
+			name: "entries outside of the working hours are ignored",
+			given: []timeEntry{
+				{Description: "night owl", Start: mustParse("2021-07-03T06:00:00Z"), End: mustParse("2021-07-03T09:30:00Z")},
+				{Description: "late", Start: mustParse("2021-07-03T17:50:00Z"), End: mustParse("2021-07-03T22:00:00Z")},
+			},
+			givenNow:  mustParse("2021-07-04T00:00:00Z"),
+			threshold: 15 * time.Minute,
+			want: []gap{
+				{Start: mustParse("2021-07-03T09:30:00Z"), End: mustParse("2021-07-03T17:50:00Z")},
+			},
+		},
+		{
+			name: "a running entry covers up to now, and nothing after now is a gap",
+			given: []timeEntry{
+				{Description: "morning", Start: mustParse("2021-07-03T09:00:00Z"), End: mustParse("2021-07-03T10:00:00Z")},
+				{Description: "still running", Start: mustParse("2021-07-03T11:00:00Z")},
+			},
+			givenNow:  mustParse("2021-07-03T14:00:00Z"),
+			threshold: 15 * time.Minute,
+			want: []gap{
+				{Start: mustParse("2021-07-03T10:00:00Z"), End: mustParse("2021-07-03T11:00:00Z")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findGaps(tt.given, from, to, tt.threshold, tt.givenNow)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_findOverlaps(t *testing.T) {
+	morning := timeEntry{Description: "morning", Start: mustParse("2021-07-03T09:00:00Z"), End: mustParse("2021-07-03T12:00:00Z")}
+	meeting := timeEntry{Description: "meeting", Start: mustParse("2021-07-03T11:30:00Z"), End: mustParse("2021-07-03T12:30:00Z")}
+	afternoon := timeEntry{Description: "afternoon", Start: mustParse("2021-07-03T12:30:00Z"), End: mustParse("2021-07-03T17:00:00Z")}
+	running := timeEntry{Description: "running", Start: mustParse("2021-07-03T16:00:00Z")}
+
+	t.Run("adjacent entries do not overlap", func(t *testing.T) {
+		got := findOverlaps([]timeEntry{afternoon, meeting}, mustParse("2021-07-04T00:00:00Z"))
+		assert.Nil(t, got)
+	})
+
+	t.Run("overlapping entries are reported in chronological order", func(t *testing.T) {
+		got := findOverlaps([]timeEntry{running, afternoon, meeting, morning}, mustParse("2021-07-03T18:00:00Z"))
+		assert.Equal(t, []overlap{
+			{First: morning, Second: meeting, Start: mustParse("2021-07-03T11:30:00Z"), End: mustParse("2021-07-03T12:00:00Z")},
+			{First: afternoon, Second: running, Start: mustParse("2021-07-03T16:00:00Z"), End: mustParse("2021-07-03T17:00:00Z")},
+		}, got)
+	})
+}
+
+func Test_workingHours(t *testing.T) {
+	day := mustParse("2021-07-03T00:00:00Z")
+
+	t.Run("defaults to 09:00 to 18:00", func(t *testing.T) {
+		start, end, err := workingHours(Config{}, day)
+		require.NoError(t, err)
+		assert.Equal(t, mustParse("2021-07-03T09:00:00Z"), start)
+		assert.Equal(t, mustParse("2021-07-03T18:00:00Z"), end)
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		_, _, err := workingHours(Config{WorkingHours: WorkingHours{Start: "18:00", End: "09:00"}}, day)
+		require.EqualError(t, err, "working-hours: end (09:00) must be after start (18:00)")
+	})
+}
+
+func Test_formatDuration(t *testing.T) {
+	assert.Equal(t, "45m", formatDuration(45*time.Minute))
+	assert.Equal(t, "2h", formatDuration(2*time.Hour))
+	assert.Equal(t, "1h05", formatDuration(65*time.Minute))
+	assert.Equal(t, "0m", formatDuration(10*time.Second))
+}
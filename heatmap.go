@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mgutz/ansi"
+)
+
+// The shades of the heatmap, from "nothing logged" to "the most logged in a
+// day". The terminal uses the greens of the 256-color palette, and the SVG
+// uses the same greens as the GitHub contribution graph.
+var (
+	heatANSI = [5]string{"237", "22", "28", "34", "40"}
+	heatSVG  = [5]string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+)
+
+// The rows of the heatmap start on Monday.
+var heatWeekdays = [7]string{"Mon", "", "Wed", "", "Fri", "", ""}
+
+// heatCell is one day of the heatmap.
+type heatCell struct {
+	Day    time.Time // Zero when the cell is not part of the heatmap.
+	Logged time.Duration
+	Level  int // From 0 (nothing logged) to 4 (close to the longest day).
+}
+
+// buildHeatmap returns the days of the year, one column per week. The first
+// column is the week of January 1st. The days after 'until' are left empty,
+// and so are the days of the first and last weeks that belong to the
+// previous or next year. The keys of 'logged' are of the form "2021-07-05".
+func buildHeatmap(year int, until time.Time, logged map[string]time.Duration) [][7]heatCell {
+	loc := until.Location()
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	last := time.Date(year, time.December, 31, 0, 0, 0, 0, loc)
+
+	var max time.Duration
+	for day, d := range logged {
+		if strings.HasPrefix(day, fmt.Sprintf("%04d-", year)) && d > max {
+			max = d
+		}
+	}
+
+	var weeks [][7]heatCell
+	for day := first.AddDate(0, 0, -weekdayIndex(first)); !day.After(last); day = day.AddDate(0, 0, 1) {
+		row := weekdayIndex(day)
+		if row == 0 {
+			weeks = append(weeks, [7]heatCell{})
+		}
+		if day.Before(first) || day.After(until) {
+			continue
+		}
+
+		d := logged[day.Format(layoutISO)]
+		level := 0
+		if d > 0 {
+			level = int(math.Ceil(4 * float64(d) / float64(max)))
+		}
+		weeks[len(weeks)-1][row] = heatCell{Day: day, Logged: d, Level: level}
+	}
+	return weeks
+}
+
+// weekdayIndex is like Weekday but starts on Monday.
+func weekdayIndex(day time.Time) int {
+	return (int(day.Weekday()) + 6) % 7
+}
+
+// heatMonths returns the columns at which each month starts. A month starts
+// at the first column that contains its first day.
+func heatMonths(weeks [][7]heatCell) map[int]time.Month {
+	months := make(map[int]time.Month)
+	for col, week := range weeks {
+		for _, cell := range week {
+			if !cell.Day.IsZero() && cell.Day.Day() == 1 {
+				months[col] = cell.Day.Month()
+			}
+		}
+	}
+	return months
+}
+
+// renderHeatmapTerminal prints the heatmap with one "■" per day, e.g.:
+//
+//      Jan     Feb     Mar
+//  Mon ■ ■ ■ ■ ■ ■ ■ ■ ■ ■
+//      ■ ■ ■ ■ ■ ■ ■ ■ ■ ■
+//  Wed ■ ■ ■ ■ ■ ■ ■ ■ ■ ■
+//  ...
+//  2021: 1203h30 over 210 day(s), the longest being 2021-07-14 (9h15)
+//
+// The 'colorize' func is given the level of the cell.
+func renderHeatmapTerminal(w io.Writer, year int, weeks [][7]heatCell, colorize func(text string, level int) string) {
+	months := heatMonths(weeks)
+	header := []rune(strings.Repeat(" ", 4+2*len(weeks)))
+	free := 0
+	for col := range weeks {
+		month, ok := months[col]
+		if !ok || 4+2*col < free {
+			continue
+		}
+		copy(header[4+2*col:], []rune(month.String()[:3]))
+		free = 4 + 2*col + 4
+	}
+	fmt.Fprintln(w, strings.TrimRight(string(header), " "))
+
+	for row := 0; row < 7; row++ {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%-3s ", heatWeekdays[row])
+		for _, week := range weeks {
+			if week[row].Day.IsZero() {
+				line.WriteString("  ")
+				continue
+			}
+			line.WriteString(colorize("■", week[row].Level) + " ")
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+
+	fmt.Fprintln(w, heatSummary(year, weeks))
+}
+
+// heatSummary is the line shown under the heatmap.
+func heatSummary(year int, weeks [][7]heatCell) string {
+	var total, longest time.Duration
+	var longestDay time.Time
+	days := 0
+	for _, week := range weeks {
+		for _, cell := range week {
+			if cell.Logged == 0 {
+				continue
+			}
+			total += cell.Logged
+			days++
+			if cell.Logged > longest {
+				longest, longestDay = cell.Logged, cell.Day
+			}
+		}
+	}
+	if days == 0 {
+		return fmt.Sprintf("%d: no time entries.", year)
+	}
+	return fmt.Sprintf("%d: %s over %d day(s), the longest being %s (%s)", year, formatDuration(total), days, longestDay.Format(layoutISO), formatDuration(longest))
+}
+
+// The size of a day in the SVG, and the space between two days.
+const (
+	heatCellSize = 10
+	heatCellGap  = 2
+)
+
+// renderHeatmapSVG writes the heatmap as a standalone SVG image. Hovering a
+// day shows how long was logged that day.
+func renderHeatmapSVG(w io.Writer, year int, weeks [][7]heatCell) {
+	const left, top, step = 30, 15, heatCellSize + heatCellGap
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="9">`+"\n",
+		left+step*len(weeks), top+step*7+15)
+
+	months := heatMonths(weeks)
+	for col := range weeks {
+		if month, ok := months[col]; ok {
+			fmt.Fprintf(w, `  <text x="%d" y="%d">%s</text>`+"\n", left+step*col, top-5, month.String()[:3])
+		}
+	}
+	for row, name := range heatWeekdays {
+		if name != "" {
+			fmt.Fprintf(w, `  <text x="0" y="%d">%s</text>`+"\n", top+step*row+heatCellSize-1, name)
+		}
+	}
+
+	for col, week := range weeks {
+		for row, cell := range week {
+			if cell.Day.IsZero() {
+				continue
+			}
+			logged := "nothing logged"
+			if cell.Logged > 0 {
+				logged = formatDuration(cell.Logged)
+			}
+			fmt.Fprintf(w, `  <rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %s</title></rect>`+"\n",
+				left+step*col, top+step*row, heatCellSize, heatCellSize, heatSVG[cell.Level], cell.Day.Format(layoutISO), logged)
+		}
+	}
+
+	fmt.Fprintf(w, `  <text x="0" y="%d">%s</text>`+"\n", top+step*7+10, heatSummary(year, weeks))
+	fmt.Fprintln(w, "</svg>")
+}
+
+func colorizeHeat(text string, level int) string {
+	return ansi.Color(text, heatANSI[level])
+}
+
+func runHeatmap(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	now := time.Now()
+
+	flags := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	year := flags.Int("year", now.Year(), "The year to show.")
+	output := flags.String("output", "terminal", "Output format, either 'terminal' or 'svg'.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *output != "terminal" && *output != "svg" {
+		return fmt.Errorf("--output must be either 'terminal' or 'svg', got '%s'", *output)
+	}
+	if *year > now.Year() {
+		return fmt.Errorf("cannot give a future year, %d is in the future", *year)
+	}
+
+	from := time.Date(*year, time.January, 1, 0, 0, 0, 0, now.Location())
+	until := endOfDay(time.Date(*year, time.December, 31, 0, 0, 0, 0, now.Location()))
+	if until.After(now) {
+		until = endOfDay(now)
+	}
+
+	entries, err := timeEntriesForRange(client, time.Now, workspaceName, from, until)
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+	entries, err = billableOnly(conf, entries)
+	if err != nil {
+		return err
+	}
+
+	weeks := buildHeatmap(*year, startOfDay(until), loggedPerDay(entries, now.Location(), now))
+	switch *output {
+	case "svg":
+		renderHeatmapSVG(os.Stdout, *year, weeks)
+	default:
+		renderHeatmapTerminal(os.Stdout, *year, weeks, colorizeHeat)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_heatmap(t *testing.T) {
+	// January 1st, 2021 is a Friday.
+	until := mustParse("2021-01-20T00:00:00Z")
+	logged := map[string]time.Duration{
+		"2020-12-31": 10 * time.Hour,
+		"2021-01-01": 2 * time.Hour,
+		"2021-01-04": 8 * time.Hour,
+		"2021-01-05": 4 * time.Hour,
+		"2021-01-12": 1 * time.Hour,
+	}
+
+	weeks := buildHeatmap(2021, until, logged)
+	assert.Len(t, weeks, 53)
+	assert.Equal(t, heatCell{}, weeks[0][3], "December 31st belongs to the previous year")
+	assert.Equal(t, heatCell{Day: mustParse("2021-01-01T00:00:00Z"), Logged: 2 * time.Hour, Level: 1}, weeks[0][4])
+	assert.Equal(t, heatCell{Day: mustParse("2021-01-04T00:00:00Z"), Logged: 8 * time.Hour, Level: 4}, weeks[1][0])
+	assert.Equal(t, heatCell{Day: mustParse("2021-01-05T00:00:00Z"), Logged: 4 * time.Hour, Level: 2}, weeks[1][1])
+	assert.Equal(t, heatCell{Day: mustParse("2021-01-12T00:00:00Z"), Logged: 1 * time.Hour, Level: 1}, weeks[2][1])
+	assert.Equal(t, heatCell{Day: mustParse("2021-01-20T00:00:00Z")}, weeks[3][2])
+	assert.Equal(t, heatCell{}, weeks[3][3], "the days after 'until' are empty")
+
+	var b strings.Builder
+	renderHeatmapTerminal(&b, 2021, weeks[:3], func(text string, level int) string {
+		return fmt.Sprint(level)
+	})
+	assert.Equal(t, heredoc.Doc(`
+		    Jan
+		Mon   4 0
+		      2 1
+		Wed   0 0
+		      0 0
+		Fri 1 0 0
+		    0 0 0
+		    0 0 0
+		2021: 15h over 4 day(s), the longest being 2021-01-04 (8h)
+	`), b.String())
+
+	b.Reset()
+	renderHeatmapSVG(&b, 2021, weeks[:3])
+	assert.Contains(t, b.String(), `<svg xmlns="http://www.w3.org/2000/svg" width="66" height="114"`)
+	assert.Contains(t, b.String(), `<text x="30" y="10">Jan</text>`)
+	assert.Contains(t, b.String(), `<rect x="30" y="63" width="10" height="10" rx="2" fill="#9be9a8"><title>2021-01-01: 2h</title></rect>`)
+	assert.Contains(t, b.String(), `<rect x="42" y="15" width="10" height="10" rx="2" fill="#216e39"><title>2021-01-04: 8h</title></rect>`)
+	assert.Contains(t, b.String(), `<rect x="54" y="27" width="10" height="10" rx="2" fill="#9be9a8"><title>2021-01-12: 1h</title></rect>`)
+	assert.Contains(t, b.String(), `<rect x="54" y="15" width="10" height="10" rx="2" fill="#ebedf0"><title>2021-01-11: nothing logged</title></rect>`)
+	assert.NotContains(t, b.String(), "2020-12-31")
+}
+
+func Test_heatmap_empty(t *testing.T) {
+	weeks := buildHeatmap(2021, mustParse("2021-12-31T00:00:00Z"), nil)
+	assert.Equal(t, "2021: no time entries.", heatSummary(2021, weeks))
+	for _, week := range weeks {
+		for _, cell := range week {
+			assert.Equal(t, 0, cell.Level)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// The changes made to the time entries are appended to this file, one JSON
+// object per line, so that they can be undone.
+const historyPath = ".config/clockidup-history.jsonl"
+
+// The kinds of operations stored in the history.
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+	opUndo   = "undo"
+)
+
+// operation is a line of the history file. The history is append-only:
+// undoing an operation appends an "undo" operation that refers to it.
+type operation struct {
+	ID          int                 `json:"id"`
+	Time        time.Time           `json:"time"`
+	Kind        string              `json:"kind"`
+	WorkspaceID string              `json:"workspaceId"`
+	EntryID     string              `json:"entryId,omitempty"`
+	Before      *clockify.TimeEntry `json:"before,omitempty"` // Nil for "create".
+	After       *clockify.TimeEntry `json:"after,omitempty"`  // Nil for "delete".
+
+	// Undoes is the ID of the undone operation. When undoing a deletion,
+	// the time entry is created again with a new ID, NewEntryID.
+	Undoes     int    `json:"undoes,omitempty"`
+	NewEntryID string `json:"newEntryId,omitempty"`
+}
+
+// readHistory returns the operations in the order they were made. A missing
+// file means that no operation was made yet.
+func readHistory(path string) ([]operation, error) {
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("while reading the history: %s", err)
+	}
+	defer f.Close()
+
+	var ops []operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var op operation
+		err = json.Unmarshal(scanner.Bytes(), &op)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: while decoding the operation from JSON: %s", path, line, err)
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("while reading the history: %s", err)
+	}
+	return ops, nil
+}
+
+// appendHistory adds the operation at the end of the history. Its ID is the
+// one following the last operation.
+func appendHistory(path string, op operation) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("while saving the history: %s", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("while saving the history: %s", err)
+	}
+	defer f.Close()
+
+	last, err := lastHistoryID(f)
+	if err != nil {
+		return fmt.Errorf("while reading the history: %s", err)
+	}
+	op.ID = last + 1
+
+	line, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("while encoding the operation to JSON: %s", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("while saving the history: %s", err)
+	}
+	return nil
+}
+
+// lastHistoryID returns the ID of the last operation in the history, or 0
+// when the history is empty. Only the last line is read, a chunk at a time
+// starting from the end of the file.
+func lastHistoryID(f *os.File) (int, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var tail []byte
+	chunk := make([]byte, 4096)
+	for off := info.Size(); off > 0; {
+		n := int64(len(chunk))
+		if off < n {
+			n = off
+		}
+		off -= n
+		_, err := f.ReadAt(chunk[:n], off)
+		if err != nil {
+			return 0, err
+		}
+		tail = append(append([]byte{}, chunk[:n]...), tail...)
+
+		trimmed := bytes.TrimRight(tail, "\n")
+		i := bytes.LastIndexByte(trimmed, '\n')
+		if i == -1 && off > 0 {
+			continue
+		}
+		if len(trimmed) == 0 {
+			return 0, nil
+		}
+		var op struct {
+			ID int `json:"id"`
+		}
+		err = json.Unmarshal(trimmed[i+1:], &op)
+		if err != nil {
+			return 0, fmt.Errorf("while decoding the last operation from JSON: %s", err)
+		}
+		return op.ID, nil
+	}
+	return 0, nil
+}
+
+// historyClient records the changes made to the time entries in the history
+// file. The state of a time entry before it is updated or deleted is fetched
+// first so that the change can be undone. The changes can be made
+// concurrently, e.g. by 'clockidup bulk'.
+type historyClient struct {
+	clockifyClient
+	path string
+	now  func() time.Time
+	mu   *sync.Mutex // Two operations must not get the same ID.
+}
+
+func withHistory(client clockifyClient, path string) clockifyClient {
+	return historyClient{clockifyClient: client, path: path, now: time.Now, mu: &sync.Mutex{}}
+}
+
+func (c historyClient) record(op operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	op.Time = c.now().UTC()
+	err := appendHistory(c.path, op)
+	if err != nil {
+		return fmt.Errorf("the change was made but could not be recorded, which means it can't be undone: %s", err)
+	}
+	return nil
+}
+
+func (c historyClient) CreateTimeEntry(workspaceID string, create clockify.CreateTimeEntryRequest) (clockify.TimeEntry, error) {
+	created, err := c.clockifyClient.CreateTimeEntry(workspaceID, create)
+	if err != nil {
+		return clockify.TimeEntry{}, err
+	}
+	return created, c.record(operation{Kind: opCreate, WorkspaceID: workspaceID, EntryID: created.ID, After: &created})
+}
+
+func (c historyClient) UpdateTimeEntry(workspaceID, timeEntryID string, update clockify.UpdateTimeEntryRequest) (clockify.TimeEntry, error) {
+	before, err := c.clockifyClient.TimeEntry(workspaceID, timeEntryID)
+	if err != nil {
+		return clockify.TimeEntry{}, fmt.Errorf("while fetching the time entry before updating it: %s", err)
+	}
+	updated, err := c.clockifyClient.UpdateTimeEntry(workspaceID, timeEntryID, update)
+	if err != nil {
+		return clockify.TimeEntry{}, err
+	}
+	return updated, c.record(operation{Kind: opUpdate, WorkspaceID: workspaceID, EntryID: timeEntryID, Before: &before, After: &updated})
+}
+
+func (c historyClient) DeleteTimeEntry(workspaceID, timeEntryID string) error {
+	before, err := c.clockifyClient.TimeEntry(workspaceID, timeEntryID)
+	if err != nil {
+		return fmt.Errorf("while fetching the time entry before deleting it: %s", err)
+	}
+	err = c.clockifyClient.DeleteTimeEntry(workspaceID, timeEntryID)
+	if err != nil {
+		return err
+	}
+	return c.record(operation{Kind: opDelete, WorkspaceID: workspaceID, EntryID: timeEntryID, Before: &before})
+}
+
+// undoable returns the operations that weren't undone yet, the most recent
+// first. The entry IDs are those of the time entries as they are now, since
+// undoing a deletion gives a new ID to the time entry.
+func undoable(ops []operation) []operation {
+	undone := make(map[int]bool)
+	renamed := make(map[string]string)
+	for _, op := range ops {
+		if op.Kind == opUndo {
+			undone[op.Undoes] = true
+			if op.NewEntryID != "" {
+				renamed[op.EntryID] = op.NewEntryID
+			}
+		}
+	}
+
+	var result []operation
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		if op.Kind == opUndo || undone[op.ID] {
+			continue
+		}
+		for renamed[op.EntryID] != "" {
+			op.EntryID = renamed[op.EntryID]
+		}
+		result = append(result, op)
+	}
+	return result
+}
+
+// undo reverts the operation using the client that doesn't record the
+// changes, and returns the "undo" operation to be added to the history.
+func undo(client clockifyClient, op operation) (operation, error) {
+	done := operation{Kind: opUndo, WorkspaceID: op.WorkspaceID, EntryID: op.EntryID, Undoes: op.ID}
+	switch op.Kind {
+	case opCreate:
+		err := client.DeleteTimeEntry(op.WorkspaceID, op.EntryID)
+		if err != nil {
+			return operation{}, fmt.Errorf("while deleting the time entry '%s': %s", describeOperation(op), err)
+		}
+	case opUpdate:
+		_, err := client.UpdateTimeEntry(op.WorkspaceID, op.EntryID, op.Before.UpdateRequest())
+		if err != nil {
+			return operation{}, fmt.Errorf("while restoring the time entry '%s': %s", describeOperation(op), err)
+		}
+	case opDelete:
+		before := op.Before.UpdateRequest()
+		created, err := client.CreateTimeEntry(op.WorkspaceID, clockify.CreateTimeEntryRequest{
+			Start:        before.Start,
+			End:          before.End,
+			Billable:     before.Billable,
+			Description:  before.Description,
+			ProjectID:    before.ProjectID,
+			TaskID:       before.TaskID,
+			TagIds:       before.TagIds,
+			CustomFields: before.CustomFields,
+		})
+		if err != nil {
+			return operation{}, fmt.Errorf("while creating the time entry '%s' again: %s", describeOperation(op), err)
+		}
+		done.NewEntryID = created.ID
+	default:
+		return operation{}, fmt.Errorf("the operation %d of kind '%s' can't be undone", op.ID, op.Kind)
+	}
+	return done, nil
+}
+
+// describeOperation returns e.g. "'reviewing PR 3574' → 'reviewing the
+// ACME PR'" for a rewording.
+func describeOperation(op operation) string {
+	describe := func(e *clockify.TimeEntry) string {
+		end := "now"
+		if !e.TimeInterval.End.IsZero() {
+			end = e.TimeInterval.End.Local().Format("15:04")
+		}
+		return fmt.Sprintf("'%s' (%s %s–%s)", e.Description, e.TimeInterval.Start.Local().Format(layoutISO), e.TimeInterval.Start.Local().Format("15:04"), end)
+	}
+	switch {
+	case op.Before != nil && op.After != nil:
+		return describe(op.Before) + " → " + describe(op.After)
+	case op.Before != nil:
+		return describe(op.Before)
+	case op.After != nil:
+		return describe(op.After)
+	default:
+		return op.EntryID
+	}
+}
+
+// undoLast reverts the last n operations, the most recent first. Each
+// undone operation is recorded right away so that the history stays right
+// when one of them fails.
+func undoLast(client clockifyClient, path string, n int, now func() time.Time) ([]operation, error) {
+	ops, err := readHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	todo := undoable(ops)
+	if len(todo) == 0 {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	if n > len(todo) {
+		n = len(todo)
+	}
+
+	var undone []operation
+	for _, op := range todo[:n] {
+		done, err := undo(client, op)
+		if err != nil {
+			return undone, err
+		}
+		done.Time = now().UTC()
+		err = appendHistory(path, done)
+		if err != nil {
+			return undone, err
+		}
+		undone = append(undone, op)
+	}
+	return undone, nil
+}
+
+// historyRows returns the rows printed by 'clockidup history', the most
+// recent first.
+func historyRows(ops []operation, limit int) [][]string {
+	undone := make(map[int]bool)
+	for _, op := range ops {
+		if op.Kind == opUndo {
+			undone[op.Undoes] = true
+		}
+	}
+
+	var rows [][]string
+	for i := len(ops) - 1; i >= 0 && len(rows) < limit; i-- {
+		op := ops[i]
+		if op.Kind == opUndo {
+			continue
+		}
+		kind := op.Kind
+		if undone[op.ID] {
+			kind += " (undone)"
+		}
+		rows = append(rows, []string{strconv.Itoa(op.ID), op.Time.Local().Format("2006-01-02 15:04"), kind, describeOperation(op)})
+	}
+	return rows
+}
+
+func defaultHistoryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding HOME: %s", err)
+	}
+	return filepath.Join(home, historyPath), nil
+}
+
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "How many operations to show.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	path, err := defaultHistoryPath()
+	if err != nil {
+		return err
+	}
+	ops, err := readHistory(path)
+	if err != nil {
+		return err
+	}
+	rows := historyRows(ops, *limit)
+	if len(rows) == 0 {
+		fmt.Println("No changes were made to the time entries yet.")
+		return nil
+	}
+	return printTable(os.Stdout, []string{"ID", "WHEN", "OPERATION", "TIME ENTRY"}, rows)
+}
+
+// runUndo is given the client that doesn't record the changes, since undoing
+// is recorded differently.
+func runUndo(client clockifyClient, args []string) error {
+	flags := flag.NewFlagSet("undo", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	n := 1
+	if flags.NArg() > 0 {
+		n, err = strconv.Atoi(flags.Arg(0))
+		if err != nil || n < 1 {
+			return fmt.Errorf("expected the number of operations to undo, e.g. 'clockidup undo 3', got '%s'", flags.Arg(0))
+		}
+	}
+
+	path, err := defaultHistoryPath()
+	if err != nil {
+		return err
+	}
+	undone, err := undoLast(client, path, n, time.Now)
+	for _, op := range undone {
+		logutil.Infof("undid %s %s", op.Kind, describeOperation(op))
+	}
+	return err
+}
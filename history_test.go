@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+// fakeTimeEntries is a Clockify server that only knows about the time
+// entries of the workspace "workspace-1-uid".
+type fakeTimeEntries struct {
+	mu      sync.Mutex
+	entries map[string]clockify.TimeEntry
+	lastID  int
+}
+
+func (f *fakeTimeEntries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const prefix = "/api/v1/workspaces/workspace-1-uid/time-entries"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(404)
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+	var req clockify.CreateTimeEntryRequest
+	if r.Method == "POST" || r.Method == "PUT" {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+	}
+	entry, found := f.entries[id]
+	if r.Method != "POST" && !found {
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"message":"TimeEntry doesn't belong to Workspace","code":501}`))
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+	case "POST", "PUT":
+		if r.Method == "POST" {
+			f.lastID++
+			id = "entry-" + strconv.Itoa(f.lastID) + "-uid"
+		}
+		entry = clockify.TimeEntry{ID: id, WorkspaceID: "workspace-1-uid", Description: req.Description, ProjectID: req.ProjectID, TaskID: req.TaskID, TagIds: req.TagIds, Billable: req.Billable}
+		entry.TimeInterval.Start = req.Start
+		if req.End != nil {
+			entry.TimeInterval.End = *req.End
+		}
+		f.entries[id] = entry
+	case "DELETE":
+		delete(f.entries, id)
+		w.WriteHeader(204)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(entry)
+}
+
+func Test_undo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clockidup-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".config", "clockidup-history.jsonl")
+
+	fake := &fakeTimeEntries{entries: map[string]clockify.TimeEntry{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client := clockify.NewClient("token", clockify.WithServer(server.URL))
+	recorded := withHistory(client, path).(historyClient)
+	recorded.now = func() time.Time { return mustParse("2021-07-05T10:00:00Z") }
+
+	start, end := mustParse("2021-07-05T09:00:00Z"), mustParse("2021-07-05T10:00:00Z")
+	created, err := recorded.CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start, End: &end, Description: "reviewing PR 3574"})
+	require.NoError(t, err)
+	update := clockify.UpdateTimeEntryRequest{Start: start, End: &end, Description: "reviewing the ACME PR"}
+	_, err = recorded.UpdateTimeEntry("workspace-1-uid", created.ID, update)
+	require.NoError(t, err)
+	require.NoError(t, recorded.DeleteTimeEntry("workspace-1-uid", created.ID))
+	assert.Empty(t, fake.entries)
+
+	ops, err := readHistory(path)
+	require.NoError(t, err)
+	require.Len(t, ops, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{ops[0].ID, ops[1].ID, ops[2].ID})
+	assert.Equal(t, []string{opCreate, opUpdate, opDelete}, []string{ops[0].Kind, ops[1].Kind, ops[2].Kind})
+	assert.Equal(t, "reviewing PR 3574", ops[1].Before.Description)
+	assert.Equal(t, "reviewing the ACME PR", ops[1].After.Description)
+
+	t.Run("undoing the deletion creates the entry again", func(t *testing.T) {
+		undone, err := undoLast(client, path, 1, time.Now)
+		require.NoError(t, err)
+		require.Len(t, undone, 1)
+		assert.Equal(t, opDelete, undone[0].Kind)
+		require.Len(t, fake.entries, 1)
+		assert.Equal(t, "reviewing the ACME PR", fake.entries["entry-2-uid"].Description)
+	})
+
+	t.Run("the next undo follows the new ID", func(t *testing.T) {
+		undone, err := undoLast(client, path, 5, time.Now)
+		require.NoError(t, err)
+		require.Len(t, undone, 2)
+		assert.Equal(t, []string{opUpdate, opCreate}, []string{undone[0].Kind, undone[1].Kind})
+		assert.Empty(t, fake.entries)
+	})
+
+	t.Run("nothing is left to undo", func(t *testing.T) {
+		_, err := undoLast(client, path, 1, time.Now)
+		require.EqualError(t, err, "nothing to undo")
+	})
+
+	t.Run("the history shows the undone operations", func(t *testing.T) {
+		ops, err := readHistory(path)
+		require.NoError(t, err)
+		require.Len(t, ops, 6)
+
+		rows := historyRows(ops, 2)
+		require.Len(t, rows, 2)
+		assert.Equal(t, []string{"3", "2"}, []string{rows[0][0], rows[1][0]})
+		assert.Equal(t, []string{"delete (undone)", "update (undone)"}, []string{rows[0][2], rows[1][2]})
+	})
+}
+
+func Test_appendHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clockidup-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history.jsonl")
+
+	// The second operation spans several of the chunks read when looking
+	// for the last ID.
+	long := &clockify.TimeEntry{Description: strings.Repeat("a", 10000)}
+	require.NoError(t, appendHistory(path, operation{Kind: opCreate, EntryID: "entry-1-uid"}))
+	require.NoError(t, appendHistory(path, operation{Kind: opCreate, EntryID: "entry-2-uid", After: long}))
+	require.NoError(t, appendHistory(path, operation{Kind: opDelete, EntryID: "entry-1-uid"}))
+
+	ops, err := readHistory(path)
+	require.NoError(t, err)
+	var ids []int
+	for _, op := range ops {
+		ids = append(ids, op.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func Test_historyClient(t *testing.T) {
+	t.Run("failed changes are not recorded", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "clockidup-")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "history.jsonl")
+
+		server := httptest.NewServer(&fakeTimeEntries{entries: map[string]clockify.TimeEntry{}})
+		defer server.Close()
+		recorded := withHistory(clockify.NewClient("token", clockify.WithServer(server.URL)), path)
+
+		err = recorded.DeleteTimeEntry("workspace-1-uid", "entry-1-uid")
+		require.EqualError(t, err, "while fetching the time entry before deleting it: 404 Not Found: TimeEntry doesn't belong to Workspace")
+
+		ops, err := readHistory(path)
+		require.NoError(t, err)
+		assert.Empty(t, ops)
+	})
+}
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// The formats understood by 'clockidup import'.
+const (
+	formatCSV         = "csv"         // What 'clockidup export' writes.
+	formatToggl       = "toggl"       // Toggl Track's "detailed report" CSV.
+	formatTimewarrior = "timewarrior" // The JSON written by 'timew export'.
+)
+
+// importedEntry is a time entry read from the file being imported. The
+// names are the ones found in the file; they are matched with the projects,
+// tasks and tags of the workspace when the entry is created.
+type importedEntry struct {
+	Where string // For example "line 3", for the error messages.
+
+	Start, End  time.Time
+	Project     string
+	Task        string
+	Description string
+	Tags        []string
+	Billable    *bool // Nil when the file doesn't say; the project decides.
+}
+
+// readCSVRecords reads a CSV file that has a header. Each record maps the
+// columns of the header, in lowercase, to their value. The byte order mark
+// that some spreadsheets and Toggl put at the start of the file is ignored.
+func readCSVRecords(r io.Reader, required ...string) ([]map[string]string, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	header, err := in.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+	for _, column := range required {
+		if !contains(header, column) {
+			return nil, fmt.Errorf("the column '%s' is missing, got %s", column, strings.Join(header, ", "))
+		}
+	}
+
+	var records []map[string]string
+	for {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		record := make(map[string]string)
+		for i, value := range row {
+			if i < len(header) {
+				record[header[i]] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// splitTags splits e.g. "meeting, blocker" into its tags.
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseCSVImport reads the CSV written by 'clockidup export'. The dates and
+// times are local. An entry that ends before it starts is taken as ending
+// the day after, e.g. 23:00 to 01:00.
+func parseCSVImport(r io.Reader) ([]importedEntry, error) {
+	records, err := readCSVRecords(r, "date", "start", "end", "description")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []importedEntry
+	for i, record := range records {
+		line := i + 2
+		if record["end"] == "" {
+			return nil, fmt.Errorf("line %d: the entry has no end, it was probably still going on when exported", line)
+		}
+		start, err := time.ParseInLocation("2006-01-02 15:04", record["date"]+" "+record["start"], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: the date and start must be of the form '2021-07-01' and '09:15', got '%s' and '%s'", line, record["date"], record["start"])
+		}
+		end, err := time.ParseInLocation("2006-01-02 15:04", record["date"]+" "+record["end"], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: the end must be of the form '10:30', got '%s'", line, record["end"])
+		}
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		entry := importedEntry{
+			Where:       fmt.Sprintf("line %d", line),
+			Start:       start,
+			End:         end,
+			Project:     record["project"],
+			Task:        record["task"],
+			Description: record["description"],
+			Tags:        splitTags(record["tags"]),
+		}
+		if record["billable"] != "" {
+			billable, err := strconv.ParseBool(record["billable"])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: billable must be true or false, got '%s'", line, record["billable"])
+			}
+			entry.Billable = &billable
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseToggl reads the CSV of Toggl Track's detailed report. The dates and
+// times are in the timezone of the Toggl profile, which is assumed to be the
+// local one.
+func parseToggl(r io.Reader) ([]importedEntry, error) {
+	records, err := readCSVRecords(r, "start date", "start time", "end date", "end time", "description")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []importedEntry
+	for i, record := range records {
+		line := i + 2
+		start, err := time.ParseInLocation("2006-01-02 15:04:05", record["start date"]+" "+record["start time"], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: the start must be of the form '2021-07-01' and '09:15:00', got '%s' and '%s'", line, record["start date"], record["start time"])
+		}
+		end, err := time.ParseInLocation("2006-01-02 15:04:05", record["end date"]+" "+record["end time"], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: the end must be of the form '2021-07-01' and '10:30:00', got '%s' and '%s'", line, record["end date"], record["end time"])
+		}
+
+		entry := importedEntry{
+			Where:       fmt.Sprintf("line %d", line),
+			Start:       start,
+			End:         end,
+			Project:     record["project"],
+			Task:        record["task"],
+			Description: record["description"],
+			Tags:        splitTags(record["tags"]),
+		}
+		switch strings.ToLower(record["billable"]) {
+		case "yes":
+			billable := true
+			entry.Billable = &billable
+		case "no":
+			billable := false
+			entry.Billable = &billable
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// timewInterval is an interval of 'timew export', e.g.:
+//
+//  {"id":1,"start":"20210701T091500Z","end":"20210701T103000Z","tags":["prod/cert-manager","review"],"annotation":"reviewing PR 3574"}
+type timewInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation"`
+}
+
+// The default --project-tag, e.g. "project:prod/cert-manager".
+const defaultProjectTag = "project:"
+
+// parseTimewarrior reads the JSON written by 'timew export'. Timewarrior has
+// no projects: the tag that starts with projectTag gives the project, the
+// other tags stay tags, and the annotation is the description. The first tag
+// can't be used since 'timew export' sorts the tags.
+func parseTimewarrior(r io.Reader, projectTag string) ([]importedEntry, error) {
+	var intervals []timewInterval
+	err := json.NewDecoder(r).Decode(&intervals)
+	if err != nil {
+		return nil, fmt.Errorf("while decoding the Timewarrior export: %s", err)
+	}
+
+	const layout = "20060102T150405Z"
+	var entries []importedEntry
+	for i, interval := range intervals {
+		n := i + 1
+		if interval.End == "" {
+			return nil, fmt.Errorf("interval %d: the interval has no end, stop the tracking before exporting", n)
+		}
+		start, err := time.Parse(layout, interval.Start)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: the start must be of the form '20210701T091500Z', got '%s'", n, interval.Start)
+		}
+		end, err := time.Parse(layout, interval.End)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: the end must be of the form '20210701T103000Z', got '%s'", n, interval.End)
+		}
+
+		entry := importedEntry{Where: fmt.Sprintf("interval %d", n), Start: start, End: end, Description: interval.Annotation}
+		for _, tag := range interval.Tags {
+			if !strings.HasPrefix(tag, projectTag) {
+				entry.Tags = append(entry.Tags, tag)
+				continue
+			}
+			if entry.Project != "" {
+				return nil, fmt.Errorf("interval %d: only one tag can start with '%s', got '%s' and '%s'", n, projectTag, projectTag+entry.Project, tag)
+			}
+			entry.Project = strings.TrimPrefix(tag, projectTag)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// The projectTag is only used with the Timewarrior format.
+func parseImport(format, projectTag string, r io.Reader) ([]importedEntry, error) {
+	switch format {
+	case formatCSV:
+		return parseCSVImport(r)
+	case formatToggl:
+		return parseToggl(r)
+	case formatTimewarrior:
+		return parseTimewarrior(r, projectTag)
+	default:
+		return nil, fmt.Errorf("--format must be one of %s, %s or %s, got '%s'", formatCSV, formatToggl, formatTimewarrior, format)
+	}
+}
+
+// duplicateKey identifies a time entry by its start, to the minute, and its
+// description, which is what the exports of the other trackers keep.
+func duplicateKey(start time.Time, description string) string {
+	return start.UTC().Truncate(time.Minute).Format(time.RFC3339) + " " + strings.TrimSpace(description)
+}
+
+// importer turns the imported entries into time entries of the workspace.
+// The projects, tasks and tags are matched by name, ignoring case. With
+// create, the missing ones are created; with dryRun, nothing is created but
+// the summary is the same.
+type importer struct {
+	client      clockifyClient
+	workspaceID string
+	create      bool
+	dryRun      bool
+
+	projects map[string]clockify.Project         // By lowercase name.
+	tasks    map[string]map[string]clockify.Task // By lowercase project name, then lowercase name.
+	tags     map[string]clockify.Tag             // By lowercase name.
+
+	// Created lists what was (or would be) created, e.g. "project
+	// 'oss/cert-manager'".
+	Created []string
+}
+
+// newImporter fetches all the projects, archived ones included, and all the
+// tags so that --create never creates a second project or tag with the name
+// of an existing one.
+func newImporter(client clockifyClient, workspaceID string, create, dryRun bool) (*importer, error) {
+	projects, err := client.Projects(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching projects: %s", err)
+	}
+	tags, err := client.Tags(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching tags: %s", err)
+	}
+
+	im := &importer{
+		client:      client,
+		workspaceID: workspaceID,
+		create:      create,
+		dryRun:      dryRun,
+		projects:    make(map[string]clockify.Project),
+		tasks:       make(map[string]map[string]clockify.Task),
+		tags:        make(map[string]clockify.Tag),
+	}
+	for _, p := range projects {
+		if _, ok := im.projects[strings.ToLower(p.Name)]; !ok || !p.Archived {
+			im.projects[strings.ToLower(p.Name)] = p
+		}
+	}
+	for _, t := range tags {
+		im.tags[strings.ToLower(t.Name)] = t
+	}
+	return im, nil
+}
+
+func (im *importer) project(name string) (clockify.Project, error) {
+	if p, ok := im.projects[strings.ToLower(name)]; ok {
+		return p, nil
+	}
+	if !im.create {
+		return clockify.Project{}, fmt.Errorf("the project '%s' does not exist, use --create to create it", name)
+	}
+
+	p := clockify.Project{Name: name}
+	if !im.dryRun {
+		var err error
+		p, err = im.client.CreateProject(im.workspaceID, name)
+		if err != nil {
+			return clockify.Project{}, fmt.Errorf("while creating the project '%s': %s", name, err)
+		}
+	}
+	im.projects[strings.ToLower(name)] = p
+	im.Created = append(im.Created, fmt.Sprintf("project '%s'", name))
+	return p, nil
+}
+
+func (im *importer) task(project clockify.Project, name string) (clockify.Task, error) {
+	tasks, ok := im.tasks[strings.ToLower(project.Name)]
+	if !ok {
+		tasks = make(map[string]clockify.Task)
+		// A project that was just created, or that would be created with
+		// --dry-run, has no tasks yet.
+		if project.ID != "" {
+			list, err := im.client.Tasks(im.workspaceID, project.ID)
+			if err != nil {
+				return clockify.Task{}, fmt.Errorf("while fetching tasks: %s", err)
+			}
+			for _, t := range list {
+				tasks[strings.ToLower(t.Name)] = t
+			}
+		}
+		im.tasks[strings.ToLower(project.Name)] = tasks
+	}
+
+	if t, ok := tasks[strings.ToLower(name)]; ok {
+		return t, nil
+	}
+	if !im.create {
+		return clockify.Task{}, fmt.Errorf("the task '%s' of the project '%s' does not exist, use --create to create it", name, project.Name)
+	}
+
+	t := clockify.Task{Name: name, ProjectID: project.ID}
+	if !im.dryRun {
+		var err error
+		t, err = im.client.CreateTask(im.workspaceID, project.ID, name)
+		if err != nil {
+			return clockify.Task{}, fmt.Errorf("while creating the task '%s': %s", name, err)
+		}
+	}
+	tasks[strings.ToLower(name)] = t
+	im.Created = append(im.Created, fmt.Sprintf("task '%s: %s'", project.Name, name))
+	return t, nil
+}
+
+func (im *importer) tag(name string) (clockify.Tag, error) {
+	if t, ok := im.tags[strings.ToLower(name)]; ok {
+		return t, nil
+	}
+	if !im.create {
+		return clockify.Tag{}, fmt.Errorf("the tag '%s' does not exist, use --create to create it", name)
+	}
+
+	t := clockify.Tag{Name: name}
+	if !im.dryRun {
+		var err error
+		t, err = im.client.CreateTag(im.workspaceID, name)
+		if err != nil {
+			return clockify.Tag{}, fmt.Errorf("while creating the tag '%s': %s", name, err)
+		}
+	}
+	im.tags[strings.ToLower(name)] = t
+	im.Created = append(im.Created, fmt.Sprintf("tag '%s'", name))
+	return t, nil
+}
+
+// request returns the request that creates the entry. When the file doesn't
+// say whether the entry is billable, it is billable when its project is.
+func (im *importer) request(entry importedEntry) (clockify.CreateTimeEntryRequest, error) {
+	end := entry.End.UTC()
+	create := clockify.CreateTimeEntryRequest{Start: entry.Start.UTC(), End: &end, Description: entry.Description}
+
+	if entry.Project != "" {
+		project, err := im.project(entry.Project)
+		if err != nil {
+			return clockify.CreateTimeEntryRequest{}, err
+		}
+		create.ProjectID, create.Billable = project.ID, project.Billable
+		if entry.Task != "" {
+			task, err := im.task(project, entry.Task)
+			if err != nil {
+				return clockify.CreateTimeEntryRequest{}, err
+			}
+			create.TaskID = task.ID
+		}
+	} else if entry.Task != "" {
+		return clockify.CreateTimeEntryRequest{}, fmt.Errorf("the task '%s' needs a project", entry.Task)
+	}
+
+	for _, name := range entry.Tags {
+		tag, err := im.tag(name)
+		if err != nil {
+			return clockify.CreateTimeEntryRequest{}, err
+		}
+		create.TagIds = append(create.TagIds, tag.ID)
+	}
+
+	if entry.Billable != nil {
+		create.Billable = *entry.Billable
+	}
+	return create, nil
+}
+
+// importSummary is what 'clockidup import' reports once done.
+type importSummary struct {
+	Imported   int
+	Duplicates int
+	Failed     int
+	Created    []string
+}
+
+func (s importSummary) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d imported, %d duplicates skipped, %d failed", s.Imported, s.Duplicates, s.Failed)
+	if len(s.Created) > 0 {
+		fmt.Fprintf(&b, "\ncreated %s", strings.Join(s.Created, ", "))
+	}
+	return b.String()
+}
+
+// importEntries creates the entries that don't exist yet in the workspace.
+// An entry exists when an entry of the workspace, or an entry before it in
+// the file, has the same start and description. The entries that can't be
+// created are reported and the others are still created.
+func importEntries(client clockifyClient, workspace clockify.Workspace, entries []importedEntry, create, dryRun bool) (importSummary, error) {
+	if len(entries) == 0 {
+		return importSummary{}, nil
+	}
+	entries = append([]importedEntry(nil), entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	first, last := entries[0].Start, entries[len(entries)-1].Start
+	existing, err := client.TimeEntries(workspace.ID, workspace.Memberships[0].UserID, startOfDay(first.Local()), endOfDay(last.Local()))
+	if err != nil {
+		return importSummary{}, fmt.Errorf("while fetching time entries: %s", err)
+	}
+	seen := make(map[string]bool)
+	for _, entry := range existing {
+		seen[duplicateKey(entry.TimeInterval.Start, entry.Description)] = true
+	}
+
+	im, err := newImporter(client, workspace.ID, create, dryRun)
+	if err != nil {
+		return importSummary{}, err
+	}
+
+	var summary importSummary
+	for _, entry := range entries {
+		key := duplicateKey(entry.Start, entry.Description)
+		if seen[key] {
+			summary.Duplicates++
+			continue
+		}
+
+		req, err := im.request(entry)
+		if err == nil && !dryRun {
+			_, err = client.CreateTimeEntry(workspace.ID, req)
+			if err != nil {
+				err = fmt.Errorf("while creating the time entry: %s", err)
+			}
+		}
+		if err != nil {
+			logutil.Errorf("%s: '%s' of %s: %s", entry.Where, entry.Description, entry.Start.Local().Format("2006-01-02 15:04"), err)
+			summary.Failed++
+			continue
+		}
+		seen[key] = true
+		summary.Imported++
+	}
+	summary.Created = im.Created
+	return summary, nil
+}
+
+func runImport(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatFlag := flags.String("format", formatCSV, "One of csv (what 'clockidup export' writes), toggl or timewarrior.")
+	createFlag := flags.Bool("create", false, "Create the projects, tasks and tags that don't exist.")
+	dryRunFlag := flags.Bool("dry-run", false, "Show what would be imported without changing anything in Clockify.")
+	projectTagFlag := flags.String("project-tag", defaultProjectTag, "With --format timewarrior, the prefix of the tag that gives the project.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected the file to import, e.g. 'clockidup import --format toggl toggl.csv'")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if *projectTagFlag == "" {
+		return fmt.Errorf("--project-tag can't be empty, e.g. '--project-tag project:'")
+	}
+	entries, err := parseImport(*formatFlag, *projectTagFlag, f)
+	if err != nil {
+		return fmt.Errorf("%s: %s", flags.Arg(0), err)
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+	summary, err := importEntries(client, workspace, entries, *createFlag, *dryRunFlag)
+	if err != nil {
+		return err
+	}
+
+	if *dryRunFlag {
+		fmt.Println("Dry run, nothing was changed in Clockify.")
+	}
+	fmt.Println(summary)
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of the %d time entries could not be imported", summary.Failed, len(entries))
+	}
+	if summary.Imported > 0 && !*dryRunFlag {
+		logutil.Infof("run 'clockidup undo %d' to remove the imported entries", summary.Imported)
+	}
+	return nil
+}
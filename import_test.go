@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+func Test_parseCSVImport(t *testing.T) {
+	yes := true
+	local := func(day, h, m int) time.Time { return time.Date(2021, 7, day, h, m, 0, 0, time.Local) }
+
+	t.Run("what 'clockidup export' writes", func(t *testing.T) {
+		got, err := parseCSVImport(strings.NewReader(heredoc.Doc(`
+			date,start,end,hours,project,task,description,billable,tags,ticket
+			2021-07-01,09:15,10:30,1.25,prod/cert-manager,1.2,reviewing PR 3574,true,"meeting, blocker",CM-42
+			2021-07-01,23:00,01:00,2.00,,,on-call,,,
+		`)))
+		require.NoError(t, err)
+		assert.Equal(t, []importedEntry{
+			{Where: "line 2", Start: local(1, 9, 15), End: local(1, 10, 30), Project: "prod/cert-manager", Task: "1.2", Description: "reviewing PR 3574", Billable: &yes, Tags: []string{"meeting", "blocker"}},
+			{Where: "line 3", Start: local(1, 23, 0), End: local(2, 1, 0), Description: "on-call"},
+		}, got)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := parseCSVImport(strings.NewReader("date,start,description\n"))
+		require.EqualError(t, err, "the column 'end' is missing, got date, start, description")
+	})
+
+	t.Run("entry still going on", func(t *testing.T) {
+		_, err := parseCSVImport(strings.NewReader("date,start,end,description\n2021-07-01,09:15,,reviewing PR 3574\n"))
+		require.EqualError(t, err, "line 2: the entry has no end, it was probably still going on when exported")
+	})
+}
+
+func Test_parseToggl(t *testing.T) {
+	yes, no := true, false
+	got, err := parseToggl(strings.NewReader("\ufeff" + heredoc.Doc(`
+		User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount (USD)
+		Jane,jane@example.com,ACME,acme/website,,fixing the footer,Yes,2021-07-01,09:15:00,2021-07-01,10:30:00,01:15:00,,125.00
+		Jane,jane@example.com,,internal,,expenses,No,2021-07-01,23:30:00,2021-07-02,00:15:00,00:45:00,admin,
+	`)))
+	require.NoError(t, err)
+	assert.Equal(t, []importedEntry{
+		{Where: "line 2", Start: time.Date(2021, 7, 1, 9, 15, 0, 0, time.Local), End: time.Date(2021, 7, 1, 10, 30, 0, 0, time.Local), Project: "acme/website", Description: "fixing the footer", Billable: &yes},
+		{Where: "line 3", Start: time.Date(2021, 7, 1, 23, 30, 0, 0, time.Local), End: time.Date(2021, 7, 2, 0, 15, 0, 0, time.Local), Project: "internal", Description: "expenses", Billable: &no, Tags: []string{"admin"}},
+	}, got)
+}
+
+func Test_parseTimewarrior(t *testing.T) {
+	t.Run("the project is given by the project tag", func(t *testing.T) {
+		// Like 'timew export', the tags are sorted.
+		got, err := parseTimewarrior(strings.NewReader(`[
+			{"id":2,"start":"20210701T091500Z","end":"20210701T103000Z","tags":["meeting","project:prod/cert-manager","review"],"annotation":"reviewing PR 3574"},
+			{"id":1,"start":"20210701T110000Z","end":"20210701T113000Z","tags":["admin"]}
+		]`), defaultProjectTag)
+		require.NoError(t, err)
+		assert.Equal(t, []importedEntry{
+			{Where: "interval 1", Start: mustParse("2021-07-01T09:15:00Z"), End: mustParse("2021-07-01T10:30:00Z"), Project: "prod/cert-manager", Tags: []string{"meeting", "review"}, Description: "reviewing PR 3574"},
+			{Where: "interval 2", Start: mustParse("2021-07-01T11:00:00Z"), End: mustParse("2021-07-01T11:30:00Z"), Tags: []string{"admin"}},
+		}, got)
+	})
+
+	t.Run("another project tag", func(t *testing.T) {
+		got, err := parseTimewarrior(strings.NewReader(`[{"id":1,"start":"20210701T091500Z","end":"20210701T103000Z","tags":["+prod/cert-manager","review"]}]`), "+")
+		require.NoError(t, err)
+		assert.Equal(t, "prod/cert-manager", got[0].Project)
+		assert.Equal(t, []string{"review"}, got[0].Tags)
+	})
+
+	t.Run("two project tags", func(t *testing.T) {
+		_, err := parseTimewarrior(strings.NewReader(`[{"id":1,"start":"20210701T091500Z","end":"20210701T103000Z","tags":["project:acme","project:admin"]}]`), defaultProjectTag)
+		require.EqualError(t, err, "interval 1: only one tag can start with 'project:', got 'project:acme' and 'project:admin'")
+	})
+
+	t.Run("interval still going on", func(t *testing.T) {
+		_, err := parseTimewarrior(strings.NewReader(`[{"id":1,"start":"20210701T091500Z"}]`), defaultProjectTag)
+		require.EqualError(t, err, "interval 1: the interval has no end, stop the tracking before exporting")
+	})
+}
+
+func Test_parseImport(t *testing.T) {
+	_, err := parseImport("harvest", defaultProjectTag, strings.NewReader(""))
+	require.EqualError(t, err, "--format must be one of csv, toggl or timewarrior, got 'harvest'")
+}
+
+func Test_importEntries(t *testing.T) {
+	workspace := clockify.Workspace{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}
+	start := mustParse("2021-07-01T09:15:00Z")
+	end := start.Add(time.Hour)
+	entries := []importedEntry{
+		{Where: "line 2", Start: start, End: end, Project: "prod/cert-manager", Task: "1.2", Description: "reviewing PR 3574"},
+		{Where: "line 3", Start: start.Add(2 * time.Hour), End: end.Add(2 * time.Hour), Project: "oss/cert-manager", Description: "triage", Tags: []string{"Meeting"}},
+		{Where: "line 4", Start: start.Add(4 * time.Hour), End: end.Add(4 * time.Hour), Project: "prod/cert-manager", Description: "already there"},
+		{Where: "line 5", Start: start.Add(2 * time.Hour), End: end.Add(2 * time.Hour), Project: "oss/cert-manager", Description: "triage"},
+	}
+	expectFetch := func(client *mocks.MockclockifyClient) {
+		existing := clockify.TimeEntry{ID: "entry-1-uid", Description: "already there"}
+		existing.TimeInterval.Start = start.Add(4*time.Hour + 30*time.Second)
+		client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", startOfDay(start.Local()), endOfDay(start.Add(4*time.Hour).Local())).Return([]clockify.TimeEntry{existing}, nil)
+		client.EXPECT().Projects("workspace-1-uid").Return([]clockify.Project{{ID: "project-1-uid", Name: "prod/cert-manager", Billable: true}}, nil)
+		client.EXPECT().Tags("workspace-1-uid").Return([]clockify.Tag{{ID: "tag-1-uid", Name: "meeting"}}, nil)
+		client.EXPECT().Tasks("workspace-1-uid", "project-1-uid").Return([]clockify.Task{{ID: "task-1-uid", Name: "1.2"}}, nil)
+	}
+
+	t.Run("the missing projects are created with --create", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		expectFetch(client)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start, End: &end, Billable: true, Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid"}).Return(clockify.TimeEntry{ID: "entry-2-uid"}, nil)
+		client.EXPECT().CreateProject("workspace-1-uid", "oss/cert-manager").Return(clockify.Project{ID: "project-2-uid", Name: "oss/cert-manager"}, nil)
+		end2 := end.Add(2 * time.Hour)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start.Add(2 * time.Hour), End: &end2, Description: "triage", ProjectID: "project-2-uid", TagIds: []string{"tag-1-uid"}}).Return(clockify.TimeEntry{ID: "entry-3-uid"}, nil)
+
+		got, err := importEntries(client, workspace, entries, true, false)
+		require.NoError(t, err)
+		assert.Equal(t, importSummary{Imported: 2, Duplicates: 2, Created: []string{"project 'oss/cert-manager'"}}, got)
+	})
+
+	t.Run("nothing is created with --dry-run", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		expectFetch(client)
+
+		got, err := importEntries(client, workspace, entries, true, true)
+		require.NoError(t, err)
+		assert.Equal(t, importSummary{Imported: 2, Duplicates: 2, Created: []string{"project 'oss/cert-manager'"}}, got)
+	})
+
+	t.Run("the entries of the missing projects fail without --create", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		expectFetch(client)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start, End: &end, Billable: true, Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid"}).Return(clockify.TimeEntry{ID: "entry-2-uid"}, nil)
+
+		got, err := importEntries(client, workspace, entries, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, importSummary{Imported: 1, Duplicates: 1, Failed: 2}, got)
+	})
+
+	t.Run("the other entries are imported when one can't be created", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		expectFetch(client)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start, End: &end, Billable: true, Description: "reviewing PR 3574", ProjectID: "project-1-uid", TaskID: "task-1-uid"}).Return(clockify.TimeEntry{}, fmt.Errorf("400 Bad Request: Time entry is locked"))
+		client.EXPECT().CreateProject("workspace-1-uid", "oss/cert-manager").Return(clockify.Project{ID: "project-2-uid", Name: "oss/cert-manager"}, nil)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", gomock.Any()).Return(clockify.TimeEntry{ID: "entry-3-uid"}, nil)
+
+		got, err := importEntries(client, workspace, entries, true, false)
+		require.NoError(t, err)
+		assert.Equal(t, importSummary{Imported: 1, Duplicates: 2, Failed: 1, Created: []string{"project 'oss/cert-manager'"}}, got)
+	})
+
+	t.Run("the projects past the first 50 are found", func(t *testing.T) {
+		var projects []clockify.Project
+		for i := 1; i <= 60; i++ {
+			projects = append(projects, clockify.Project{ID: fmt.Sprintf("project-%d-uid", i), Name: fmt.Sprintf("project-%d", i)})
+		}
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", startOfDay(start.Local()), endOfDay(start.Local())).Return(nil, nil)
+		client.EXPECT().Projects("workspace-1-uid").Return(projects, nil)
+		client.EXPECT().Tags("workspace-1-uid").Return(nil, nil)
+		client.EXPECT().CreateTimeEntry("workspace-1-uid", clockify.CreateTimeEntryRequest{Start: start, End: &end, Description: "triage", ProjectID: "project-60-uid"}).Return(clockify.TimeEntry{ID: "entry-2-uid"}, nil)
+
+		got, err := importEntries(client, workspace, []importedEntry{{Where: "line 2", Start: start, End: end, Project: "Project-60", Description: "triage"}}, true, false)
+		require.NoError(t, err)
+		assert.Equal(t, importSummary{Imported: 1}, got)
+	})
+}
+
+func Test_importSummary_String(t *testing.T) {
+	assert.Equal(t, "3 imported, 1 duplicates skipped, 0 failed\ncreated project 'acme', tag 'meeting'",
+		importSummary{Imported: 3, Duplicates: 1, Created: []string{"project 'acme'", "tag 'meeting'"}}.String())
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/maelvls/clockidup/logutil"
+)
+
+const (
+	// The last invoice number is kept in this file so that each invoice
+	// gets the next number.
+	invoiceStatePath = ".config/clockidup-invoices.yml"
+
+	defaultInvoiceNumberFormat = "INV-%04d"
+)
+
+// The default templates of 'clockidup invoice'. See invoice for what the
+// templates are given.
+const (
+	defaultInvoiceMarkdown = `# Invoice {{ .Number }}
+
+Date: {{ .Date.Format "2006-01-02" }}
+Period: {{ .From.Format "2006-01-02" }} to {{ .To.Format "2006-01-02" }}
+{{- if .Issuer }}
+
+**From:**\
+{{ range $i, $line := .Issuer }}{{ if $i }}\
+{{ end }}{{ $line }}{{ end }}
+{{- end }}
+
+**To:**\
+{{ .Client }}{{ range .ClientDetails }}\
+{{ . }}{{ end }}
+
+| Item | Hours | Rate | Amount |
+|------|------:|-----:|-------:|
+{{- range .Items }}
+| {{ .Name }} | {{ hours .Hours }} | {{ money .Rate }} | {{ money .Amount }} |
+{{- end }}
+
+Subtotal: {{ money .Subtotal }}
+Tax ({{ .TaxRate }}%): {{ money .Tax }}
+**Total: {{ money .Total }}**
+`
+
+	defaultInvoiceHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{ .Number }}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 0.4em; border-bottom: 1px solid #ddd; text-align: left; }
+.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Invoice {{ .Number }}</h1>
+<p>Date: {{ .Date.Format "2006-01-02" }}<br>Period: {{ .From.Format "2006-01-02" }} to {{ .To.Format "2006-01-02" }}</p>
+{{- if .Issuer }}
+<p><strong>From:</strong><br>{{ range .Issuer }}{{ . }}<br>{{ end }}</p>
+{{- end }}
+<p><strong>To:</strong><br>{{ .Client }}<br>{{ range .ClientDetails }}{{ . }}<br>{{ end }}</p>
+<table>
+<tr><th>Item</th><th class="num">Hours</th><th class="num">Rate</th><th class="num">Amount</th></tr>
+{{- range .Items }}
+<tr><td>{{ .Name }}</td><td class="num">{{ hours .Hours }}</td><td class="num">{{ money .Rate }}</td><td class="num">{{ money .Amount }}</td></tr>
+{{- end }}
+<tr><td colspan="3" class="num">Subtotal</td><td class="num">{{ money .Subtotal }}</td></tr>
+<tr><td colspan="3" class="num">Tax ({{ .TaxRate }}%)</td><td class="num">{{ money .Tax }}</td></tr>
+<tr><th colspan="3" class="num">Total</th><th class="num">{{ money .Total }}</th></tr>
+</table>
+</body>
+</html>
+`
+)
+
+// invoice is what the invoice templates are given. The amounts are in cents
+// and can be shown with {{ money .Total }}; the hours can be shown with
+// {{ hours .Hours }}, e.g. "4.50".
+type invoice struct {
+	Number   string
+	Date     time.Time // The day the invoice is issued.
+	From, To time.Time
+
+	Issuer        []string // The lines of 'invoice.issuer' in the config.
+	Client        string
+	ClientDetails []string // The lines of the client's note in Clockify.
+
+	Items    []invoiceItem
+	Currency string
+
+	Subtotal int64
+	TaxRate  float64 // In percent, e.g. 20.
+	Tax      int64
+	Total    int64
+}
+
+// invoiceItem is the billable time spent on a task, or on a project when
+// the entries have no task.
+type invoiceItem struct {
+	Project string
+	Task    string
+	Hours   time.Duration
+	Rate    int64 // Per hour.
+	Amount  int64
+}
+
+// Name is e.g. "prod/cert-manager: reviewing" or "prod/cert-manager".
+func (i invoiceItem) Name() string {
+	if i.Task == "" {
+		return i.Project
+	}
+	return i.Project + ": " + i.Task
+}
+
+// buildInvoice groups the billable entries by project and task. All the
+// entries must have a rate, and the rates must all be in the same currency.
+func buildInvoice(entries []timeEntry, taxRate float64) (invoice, error) {
+	type key struct {
+		project, task string
+		rate          int
+	}
+	items := make(map[key]*invoiceItem)
+	var currencies []string
+	var inv invoice
+	for _, entry := range entries {
+		if !entry.Billable {
+			continue
+		}
+		project := entry.Project
+		if project == "" {
+			project = "no-project"
+		}
+		if entry.Rate.Amount == 0 {
+			return invoice{}, fmt.Errorf("no hourly rate is set for the project '%s' in Clockify", project)
+		}
+		if !contains(currencies, entry.Rate.Currency) {
+			currencies = append(currencies, entry.Rate.Currency)
+		}
+
+		k := key{project: project, task: entry.Task, rate: entry.Rate.Amount}
+		item, ok := items[k]
+		if !ok {
+			item = &invoiceItem{Project: project, Task: entry.Task, Rate: int64(entry.Rate.Amount)}
+			items[k] = item
+		}
+		item.Hours += entry.Duration
+		item.Amount += entry.Earned
+	}
+
+	if len(currencies) > 1 {
+		sort.Strings(currencies)
+		return invoice{}, fmt.Errorf("the billable entries use several currencies (%s), which can't be put on the same invoice", strings.Join(currencies, ", "))
+	}
+	if len(currencies) == 1 {
+		inv.Currency = currencies[0]
+	}
+
+	for _, item := range items {
+		inv.Items = append(inv.Items, *item)
+		inv.Subtotal += item.Amount
+	}
+	sort.Slice(inv.Items, func(i, j int) bool {
+		if inv.Items[i].Name() != inv.Items[j].Name() {
+			return inv.Items[i].Name() < inv.Items[j].Name()
+		}
+		return inv.Items[i].Rate < inv.Items[j].Rate
+	})
+
+	inv.TaxRate = taxRate
+	inv.Tax = int64(math.Round(float64(inv.Subtotal) * taxRate / 100))
+	inv.Total = inv.Subtotal + inv.Tax
+	return inv, nil
+}
+
+// invoiceFuncs are the functions that the invoice templates can use.
+func invoiceFuncs(inv invoice) map[string]interface{} {
+	return map[string]interface{}{
+		"money": func(cents int64) string { return formatMoney(cents, inv.Currency) },
+		"hours": func(d time.Duration) string { return strconv.FormatFloat(d.Hours(), 'f', 2, 64) },
+	}
+}
+
+func renderInvoiceMarkdown(w io.Writer, tmplStr string, inv invoice) error {
+	tmpl, err := template.New("invoice").Funcs(invoiceFuncs(inv)).Parse(tmplStr)
+	if err != nil {
+		return fmt.Errorf("invoice: template: %s", err)
+	}
+	err = tmpl.Execute(w, inv)
+	if err != nil {
+		return fmt.Errorf("invoice: template: %s", err)
+	}
+	return nil
+}
+
+// renderInvoiceHTML is like renderInvoiceMarkdown except that the values are
+// escaped.
+func renderInvoiceHTML(w io.Writer, tmplStr string, inv invoice) error {
+	tmpl, err := htmltemplate.New("invoice").Funcs(invoiceFuncs(inv)).Parse(tmplStr)
+	if err != nil {
+		return fmt.Errorf("invoice: html template: %s", err)
+	}
+	err = tmpl.Execute(w, inv)
+	if err != nil {
+		return fmt.Errorf("invoice: html template: %s", err)
+	}
+	return nil
+}
+
+// invoiceState is what is stored in invoiceStatePath.
+type invoiceState struct {
+	LastNumber int `yaml:"last-number"`
+}
+
+// lastInvoiceNumber returns zero when no invoice was issued yet.
+func lastInvoiceNumber(path string) (int, error) {
+	bytes, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("while reading the invoice number: %s", err)
+	}
+
+	var state invoiceState
+	err = yaml.Unmarshal(bytes, &state)
+	if err != nil {
+		return 0, fmt.Errorf("while decoding '%s' from YAML: %s", path, err)
+	}
+	return state.LastNumber, nil
+}
+
+func saveInvoiceNumber(path string, number int) error {
+	bytes, err := yaml.Marshal(invoiceState{LastNumber: number})
+	if err != nil {
+		return fmt.Errorf("encoding into YAML: %s", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("while saving the invoice number: %s", err)
+	}
+	err = ioutil.WriteFile(path, bytes, 0600)
+	if err != nil {
+		return fmt.Errorf("while saving the invoice number: %s", err)
+	}
+	return nil
+}
+
+// nonEmptyLines splits the text into lines, leaving out the empty ones.
+func nonEmptyLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// readTemplate returns the content of the template file, or the default
+// template when no file is configured.
+func readTemplate(path, defaultTmpl string) (string, error) {
+	if path == "" {
+		return defaultTmpl, nil
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding HOME: %s", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("while reading the invoice template: %s", err)
+	}
+	return string(bytes), nil
+}
+
+// entriesOfClient returns the entries of the projects of the client. The
+// entries still going on are left out since they are not done yet.
+func entriesOfClient(entries []timeEntry, clientName string) []timeEntry {
+	var selected []timeEntry
+	for _, entry := range entries {
+		if entry.Client == clientName && !entry.End.IsZero() {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+func runInvoice(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("invoice", flag.ContinueOnError)
+	clientFlag := flags.String("client", "", "Name of the client, as in 'clockidup clients'.")
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	dir := flags.String("dir", ".", "Directory in which the invoice files are written.")
+	dryRun := flags.Bool("dry-run", false, "Print the Markdown invoice instead of writing the files. The invoice number is not used up.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *clientFlag == "" || *fromFlag == "" {
+		return fmt.Errorf("--client and --from are required, e.g. 'clockidup invoice --client Acme --from 2021-07-01 --to 2021-07-31'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+
+	mdTmpl, err := readTemplate(conf.Invoice.Template, defaultInvoiceMarkdown)
+	if err != nil {
+		return err
+	}
+	htmlTmpl, err := readTemplate(conf.Invoice.HTMLTemplate, defaultInvoiceHTML)
+	if err != nil {
+		return err
+	}
+	numberFormat := conf.Invoice.NumberFormat
+	if numberFormat == "" {
+		numberFormat = defaultInvoiceNumberFormat
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+	clients, err := client.Clients(workspace.ID)
+	if err != nil {
+		return fmt.Errorf("while fetching clients: %s", err)
+	}
+	var clientName, clientNote string
+	for _, c := range clients {
+		if strings.EqualFold(c.Name, *clientFlag) {
+			clientName, clientNote = c.Name, c.Note
+			break
+		}
+	}
+	if clientName == "" {
+		return fmt.Errorf("no client named '%s' was found, see 'clockidup clients'", *clientFlag)
+	}
+
+	entries, err := timeEntriesForRange(client, time.Now, workspaceName, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	inv, err := buildInvoice(entriesOfClient(entries, clientName), conf.Invoice.TaxRate)
+	if err != nil {
+		return err
+	}
+	if len(inv.Items) == 0 {
+		return fmt.Errorf("no billable entries for the client '%s' between %s and %s", *clientFlag, from.Format(layoutISO), to.Format(layoutISO))
+	}
+	inv.Date = startOfDay(time.Now())
+	inv.From, inv.To = startOfDay(from), startOfDay(to)
+	inv.Issuer = nonEmptyLines(conf.Invoice.Issuer)
+	inv.Client = *clientFlag
+	inv.ClientDetails = nonEmptyLines(clientNote)
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("finding HOME: %s", err)
+	}
+	statePath := filepath.Join(home, invoiceStatePath)
+	last, err := lastInvoiceNumber(statePath)
+	if err != nil {
+		return err
+	}
+	inv.Number = fmt.Sprintf(numberFormat, last+1)
+
+	if *dryRun {
+		return renderInvoiceMarkdown(os.Stdout, mdTmpl, inv)
+	}
+
+	var md, html strings.Builder
+	err = renderInvoiceMarkdown(&md, mdTmpl, inv)
+	if err != nil {
+		return err
+	}
+	err = renderInvoiceHTML(&html, htmlTmpl, inv)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(*dir, 0755)
+	if err != nil {
+		return fmt.Errorf("while creating the invoice directory: %s", err)
+	}
+	for ext, content := range map[string]string{".md": md.String(), ".html": html.String()} {
+		err = ioutil.WriteFile(filepath.Join(*dir, inv.Number+ext), []byte(content), 0644)
+		if err != nil {
+			return fmt.Errorf("while writing the invoice: %s", err)
+		}
+	}
+
+	// The number is only used up once the invoice was written.
+	err = saveInvoiceNumber(statePath, last+1)
+	if err != nil {
+		return err
+	}
+
+	logutil.Infof("invoice %s written to %s (total: %s)", inv.Number, filepath.Join(*dir, inv.Number+".{md,html}"), formatMoney(inv.Total, inv.Currency))
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+func Test_buildInvoice(t *testing.T) {
+	usd := clockify.HourlyRate{Amount: 10000, Currency: "USD"}
+	entries := []timeEntry{
+		{Project: "prod/cert-manager", Task: "reviewing", Billable: true, Duration: 2 * time.Hour, Rate: usd, Earned: 20000},
+		{Project: "prod/cert-manager", Billable: true, Duration: 90 * time.Minute, Rate: usd, Earned: 15000},
+		{Project: "admin", Duration: time.Hour},
+		{Project: "prod/cert-manager", Task: "reviewing", Billable: true, Duration: 30 * time.Minute, Rate: usd, Earned: 5000},
+	}
+
+	got, err := buildInvoice(entries, 20)
+	require.NoError(t, err)
+	assert.Equal(t, invoice{
+		Items: []invoiceItem{
+			{Project: "prod/cert-manager", Hours: 90 * time.Minute, Rate: 10000, Amount: 15000},
+			{Project: "prod/cert-manager", Task: "reviewing", Hours: 150 * time.Minute, Rate: 10000, Amount: 25000},
+		},
+		Currency: "USD",
+		Subtotal: 40000,
+		TaxRate:  20,
+		Tax:      8000,
+		Total:    48000,
+	}, got)
+
+	_, err = buildInvoice(append(entries, timeEntry{Project: "support", Billable: true, Duration: time.Hour}), 20)
+	require.EqualError(t, err, "no hourly rate is set for the project 'support' in Clockify")
+
+	eur := clockify.HourlyRate{Amount: 8000, Currency: "EUR"}
+	_, err = buildInvoice(append(entries, timeEntry{Project: "support", Billable: true, Duration: time.Hour, Rate: eur, Earned: 8000}), 20)
+	require.EqualError(t, err, "the billable entries use several currencies (EUR, USD), which can't be put on the same invoice")
+}
+
+func Test_entriesOfClient(t *testing.T) {
+	end := mustParse("2021-07-01T10:00:00Z")
+	entries := []timeEntry{
+		{Description: "fixing the footer", Project: "website", Client: "ACME", End: end},
+		{Description: "fixing the header", Project: "website", Client: "Globex", End: end},
+		{Description: "still going on", Project: "website", Client: "ACME"},
+		{Description: "expenses", Project: "admin", End: end},
+	}
+
+	assert.Equal(t, []timeEntry{
+		{Description: "fixing the footer", Project: "website", Client: "ACME", End: end},
+	}, entriesOfClient(entries, "ACME"))
+}
+
+func Test_renderInvoice(t *testing.T) {
+	inv := invoice{
+		Number:        "INV-0007",
+		Date:          mustParse("2021-08-02T00:00:00Z"),
+		From:          mustParse("2021-07-01T00:00:00Z"),
+		To:            mustParse("2021-07-31T00:00:00Z"),
+		Issuer:        []string{"Jane Doe", "1 Main Street"},
+		Client:        "Acme & Co",
+		ClientDetails: []string{"2 Side Street"},
+		Items:         []invoiceItem{{Project: "prod/cert-manager", Task: "reviewing", Hours: 150 * time.Minute, Rate: 10000, Amount: 25000}},
+		Currency:      "USD",
+		Subtotal:      25000,
+		TaxRate:       20,
+		Tax:           5000,
+		Total:         30000,
+	}
+
+	var b strings.Builder
+	require.NoError(t, renderInvoiceMarkdown(&b, defaultInvoiceMarkdown, inv))
+	assert.Equal(t, heredoc.Doc(`
+		# Invoice INV-0007
+
+		Date: 2021-08-02
+		Period: 2021-07-01 to 2021-07-31
+
+		**From:**\
+		Jane Doe\
+		1 Main Street
+
+		**To:**\
+		Acme & Co\
+		2 Side Street
+
+		| Item | Hours | Rate | Amount |
+		|------|------:|-----:|-------:|
+		| prod/cert-manager: reviewing | 2.50 | 100.00 USD | 250.00 USD |
+
+		Subtotal: 250.00 USD
+		Tax (20%): 50.00 USD
+		**Total: 300.00 USD**
+	`), b.String())
+
+	b.Reset()
+	require.NoError(t, renderInvoiceHTML(&b, defaultInvoiceHTML, inv))
+	assert.Contains(t, b.String(), "<title>Invoice INV-0007</title>")
+	assert.Contains(t, b.String(), "<br>Acme &amp; Co<br>2 Side Street<br>")
+	assert.Contains(t, b.String(), `<tr><td>prod/cert-manager: reviewing</td><td class="num">2.50</td><td class="num">100.00 USD</td><td class="num">250.00 USD</td></tr>`)
+	assert.Contains(t, b.String(), `<th class="num">300.00 USD</th>`)
+
+	err := renderInvoiceMarkdown(&b, "{{ .Nope }}", inv)
+	require.EqualError(t, err, `invoice: template: template: invoice:1:3: executing "invoice" at <.Nope>: can't evaluate field Nope in type main.invoice`)
+}
+
+func Test_invoiceNumber(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clockidup-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".config", "clockidup-invoices.yml")
+
+	last, err := lastInvoiceNumber(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, last)
+
+	require.NoError(t, saveInvoiceNumber(path, 7))
+	last, err = lastInvoiceNumber(path)
+	require.NoError(t, err)
+	assert.Equal(t, 7, last)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/maelvls/clockidup/logutil"
+)
+
+const (
+	defaultJournalHeading = "## Standup"
+
+	// The standup is put between these markers so that running 'clockidup
+	// journal' again replaces the standup instead of adding a second one.
+	journalStartMarker = "<!-- clockidup:start -->"
+	journalEndMarker   = "<!-- clockidup:end -->"
+)
+
+// journalPath expands the path template, e.g. "~/notes/{{.Date}}.md". The
+// template can use .Date (2021-01-28), .Year (2021), .Month (01), .Day (28)
+// and .Weekday (Thursday).
+func journalPath(pathTmpl string, day time.Time) (string, error) {
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(pathTmpl)
+	if err != nil {
+		return "", fmt.Errorf("journal: path: %s", err)
+	}
+
+	var b strings.Builder
+	err = tmpl.Execute(&b, struct{ Date, Year, Month, Day, Weekday string }{
+		Date:    day.Format(layoutISO),
+		Year:    day.Format("2006"),
+		Month:   day.Format("01"),
+		Day:     day.Format("02"),
+		Weekday: day.Format("Monday"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("journal: path: %s", err)
+	}
+	path := b.String()
+
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding HOME: %s", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+
+	return path, nil
+}
+
+// upsertJournal puts the standup between the clockidup markers. When the
+// markers are not found, the standup is inserted right after the heading;
+// when the heading is not found either, the heading and the standup are
+// appended to the end of the note.
+func upsertJournal(note, heading, standup string) string {
+	block := journalStartMarker + "\n" + standup
+	if !strings.HasSuffix(block, "\n") {
+		block += "\n"
+	}
+	block += journalEndMarker
+
+	start := strings.Index(note, journalStartMarker)
+	end := strings.Index(note, journalEndMarker)
+	if start != -1 && end > start {
+		return note[:start] + block + note[end+len(journalEndMarker):]
+	}
+
+	lines := strings.SplitAfter(note, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != heading {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			lines[i] += "\n"
+		}
+		return strings.Join(lines[:i+1], "") + block + "\n" + strings.Join(lines[i+1:], "")
+	}
+
+	switch {
+	case note == "":
+	case strings.HasSuffix(note, "\n\n"):
+	case strings.HasSuffix(note, "\n"):
+		note += "\n"
+	default:
+		note += "\n\n"
+	}
+	return note + heading + "\n" + block + "\n"
+}
+
+func runJournal(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("journal", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if conf.Journal.Path == "" {
+		return fmt.Errorf("no journal configured, add 'journal: {path: ~/notes/{{.Date}}.md}' to ~/%s", confPath)
+	}
+	heading := conf.Journal.Heading
+	if heading == "" {
+		heading = defaultJournalHeading
+	}
+
+	dayArg := "today"
+	if flags.NArg() > 0 {
+		dayArg = flags.Arg(0)
+	}
+	day, err := parseDay(dayArg)
+	if err != nil {
+		return err
+	}
+
+	path, err := journalPath(conf.Journal.Path, day)
+	if err != nil {
+		return err
+	}
+
+	l, err := newLinker(conf)
+	if err != nil {
+		return err
+	}
+	format, err := linkFormat(conf.Links)
+	if err != nil {
+		return err
+	}
+
+	msg, err := standupForDay(client, conf, workspaceName, day)
+	if err != nil {
+		return err
+	}
+	msg = l.message(msg, format)
+
+	existing, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return fmt.Errorf("while creating the journal directory: %s", err)
+		}
+	case err != nil:
+		return fmt.Errorf("while reading the journal: %s", err)
+	}
+
+	err = ioutil.WriteFile(path, []byte(upsertJournal(string(existing), heading, msg.String())), 0644)
+	if err != nil {
+		return fmt.Errorf("while writing the journal: %s", err)
+	}
+
+	logutil.Infof("standup written to %s", path)
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_upsertJournal(t *testing.T) {
+	standup := heredoc.Doc(`
+		Monday:
+		- [1.2] prod/cert-manager: reviewing PR 3574
+	`)
+
+	tests := []struct {
+		name  string
+		given string
+		want  string
+	}{
+		{
+			name:  "empty note",
+			given: "",
+			want: heredoc.Doc(`
+				## Standup
+				<!-- clockidup:start -->
+				Monday:
+				- [1.2] prod/cert-manager: reviewing PR 3574
+				<!-- clockidup:end -->
+			`),
+		},
+		{
+			name: "heading not found, the standup is appended",
+			given: heredoc.Doc(`
+				# 2021-07-05
+				Some notes.`),
+			want: heredoc.Doc(`
+				# 2021-07-05
+				Some notes.
+
+				## Standup
+				<!-- clockidup:start -->
+				Monday:
+				- [1.2] prod/cert-manager: reviewing PR 3574
+				<!-- clockidup:end -->
+			`),
+		},
+		{
+			name: "heading found, the standup is inserted right after it",
+			given: heredoc.Doc(`
+				# 2021-07-05
+				## Standup
+				## Todo
+				- buy milk
+			`),
+			want: heredoc.Doc(`
+				# 2021-07-05
+				## Standup
+				<!-- clockidup:start -->
+				Monday:
+				- [1.2] prod/cert-manager: reviewing PR 3574
+				<!-- clockidup:end -->
+				## Todo
+				- buy milk
+			`),
+		},
+		{
+			name: "markers found, the standup is replaced",
+			given: heredoc.Doc(`
+				# 2021-07-05
+				## Standup
+				<!-- clockidup:start -->
+				Monday:
+				- [.2] prod/cert-manager: old entry
+				<!-- clockidup:end -->
+				## Todo
+				- buy milk
+			`),
+			want: heredoc.Doc(`
+				# 2021-07-05
+				## Standup
+				<!-- clockidup:start -->
+				Monday:
+				- [1.2] prod/cert-manager: reviewing PR 3574
+				<!-- clockidup:end -->
+				## Todo
+				- buy milk
+			`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := upsertJournal(tt.given, "## Standup", standup)
+			assert.Equal(t, tt.want, got)
+
+			// Running it twice must not change anything.
+			assert.Equal(t, tt.want, upsertJournal(got, "## Standup", standup))
+		})
+	}
+}
+
+func Test_journalPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	got, err := journalPath("~/notes/{{.Year}}/{{.Date}} {{.Weekday}}.md", mustParse("2021-07-05T00:00:00Z"))
+	require.NoError(t, err)
+	assert.Equal(t, home+"/notes/2021/2021-07-05 Monday.md", got)
+
+	_, err = journalPath("{{.Unknown}}.md", mustParse("2021-07-05T00:00:00Z"))
+	require.Error(t, err)
+}
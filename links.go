@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The formats that the issue references can be turned into.
+const (
+	linksNone     = "none"
+	linksMarkdown = "markdown" // [#3444](https://github.com/cert-manager/cert-manager/issues/3444)
+	linksSlack    = "slack"    // <https://github.com/cert-manager/cert-manager/issues/3444|#3444>
+)
+
+// issueRef matches GitHub-style references such as "#3444". The character
+// before "#" is captured so that "a#1" or "&#39;" are not taken for
+// references.
+var issueRef = regexp.MustCompile(`(^|[^\w&])(#(\d+))\b`)
+
+// linker turns the issue and pull request references found in the standup
+// lines into links.
+type linker struct {
+	// Maps the project name to the base URL of its repository, e.g.
+	// "https://github.com/cert-manager/cert-manager".
+	projects map[string]string
+	jira     []jiraRule
+}
+
+type jiraRule struct {
+	match *regexp.Regexp
+	url   string // The matched text is appended to the url.
+}
+
+func newLinker(conf Config) (linker, error) {
+	l := linker{projects: make(map[string]string)}
+	for project, repo := range conf.Links.Projects {
+		if !strings.Contains(repo, "://") {
+			repo = "https://" + repo
+		}
+		repo = strings.TrimSuffix(repo, "/")
+
+		l.projects[project] = repo
+
+		// The entries show the alias of the project when there is one.
+		if alias, ok := conf.ProjectAliases[project]; ok {
+			l.projects[alias] = repo
+		}
+	}
+
+	for i, rule := range conf.Links.Jira {
+		if rule.Match == "" || rule.URL == "" {
+			return linker{}, fmt.Errorf("links: jira rule #%d: match and url are required", i+1)
+		}
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return linker{}, fmt.Errorf("links: jira rule #%d: match: %s", i+1, err)
+		}
+		l.jira = append(l.jira, jiraRule{match: re, url: rule.URL})
+	}
+
+	return l, nil
+}
+
+// linkFormat returns the format given with --links, or the one from the
+// config when --links is not given.
+func linkFormat(conf Links) (string, error) {
+	format := *linksFlag
+	if format == "" {
+		format = conf.Format
+	}
+	switch format {
+	case "":
+		return linksNone, nil
+	case linksNone, linksMarkdown, linksSlack:
+		return format, nil
+	default:
+		return "", fmt.Errorf("links: format must be one of 'none', 'markdown' or 'slack', got '%s'", format)
+	}
+}
+
+// message returns a copy of the message in which the references are links.
+// The lines that were made from a time entry get the links of the entry's
+// project; the other lines (e.g. the blockers) only get the Jira links.
+func (l linker) message(msg message, format string) message {
+	if format == linksNone {
+		return msg
+	}
+
+	linked := message{Footer: msg.Footer}
+	for _, s := range msg.Sections {
+		ls := section{Title: s.Title, Entries: s.Entries}
+		for i, line := range s.Lines {
+			project := ""
+			if i < len(s.Entries) {
+				project = s.Entries[i].Project
+			}
+			ls.Lines = append(ls.Lines, l.line(line, project, format))
+		}
+		linked.Sections = append(linked.Sections, ls)
+	}
+	return linked
+}
+
+// lines is like message but for lines that are not tied to a project.
+func (l linker) lines(lines []string, format string) []string {
+	if format == linksNone {
+		return lines
+	}
+
+	var linked []string
+	for _, line := range lines {
+		linked = append(linked, l.line(line, "", format))
+	}
+	return linked
+}
+
+func (l linker) line(line, project, format string) string {
+	type ref struct {
+		start, end int
+		text, url  string
+	}
+	var refs []ref
+
+	if repo, ok := l.projects[project]; ok {
+		for _, m := range issueRef.FindAllStringSubmatchIndex(line, -1) {
+			// GitHub redirects /issues/N to /pull/N when N is a
+			// pull request.
+			refs = append(refs, ref{start: m[4], end: m[5], text: line[m[4]:m[5]], url: repo + "/issues/" + line[m[6]:m[7]]})
+		}
+	}
+	for _, rule := range l.jira {
+		for _, m := range rule.match.FindAllStringIndex(line, -1) {
+			refs = append(refs, ref{start: m[0], end: m[1], text: line[m[0]:m[1]], url: rule.url + line[m[0]:m[1]]})
+		}
+	}
+
+	// When two references overlap, the first one wins.
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].start < refs[j].start })
+
+	var b strings.Builder
+	pos := 0
+	for _, r := range refs {
+		if r.start < pos {
+			continue
+		}
+		b.WriteString(line[pos:r.start])
+		switch format {
+		case linksMarkdown:
+			fmt.Fprintf(&b, "[%s](%s)", r.text, r.url)
+		case linksSlack:
+			fmt.Fprintf(&b, "<%s|%s>", r.url, r.text)
+		}
+		pos = r.end
+	}
+	b.WriteString(line[pos:])
+
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_linker(t *testing.T) {
+	l, err := newLinker(Config{
+		ProjectAliases: map[string]string{"prod/cert-manager": "cert-manager"},
+		Links: Links{
+			Projects: map[string]string{
+				"prod/cert-manager": "github.com/cert-manager/cert-manager",
+				"prod/website":      "https://github.com/cert-manager/website/",
+			},
+			Jira: []JiraLink{{Match: `\bPROJ-\d+\b`, URL: "https://example.atlassian.net/browse/"}},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		line    string
+		project string
+		format  string
+		want    string
+	}{
+		{
+			name:    "markdown",
+			line:    "[1.2] prod/website: reviewing #3574 and #12 for PROJ-123",
+			project: "prod/website",
+			format:  linksMarkdown,
+			want:    "[1.2] prod/website: reviewing [#3574](https://github.com/cert-manager/website/issues/3574) and [#12](https://github.com/cert-manager/website/issues/12) for [PROJ-123](https://example.atlassian.net/browse/PROJ-123)",
+		},
+		{
+			name:    "slack",
+			line:    "[1.2] prod/website: #3574: fix PROJ-123",
+			project: "prod/website",
+			format:  linksSlack,
+			want:    "[1.2] prod/website: <https://github.com/cert-manager/website/issues/3574|#3574>: fix <https://example.atlassian.net/browse/PROJ-123|PROJ-123>",
+		},
+		{
+			name:    "the alias of the project is known",
+			line:    "[1.2] cert-manager: #3574",
+			project: "cert-manager",
+			format:  linksMarkdown,
+			want:    "[1.2] cert-manager: [#3574](https://github.com/cert-manager/cert-manager/issues/3574)",
+		},
+		{
+			name:    "project without repository",
+			line:    "[1.2] admin: #3574 and PROJ-1",
+			project: "admin",
+			format:  linksMarkdown,
+			want:    "[1.2] admin: #3574 and [PROJ-1](https://example.atlassian.net/browse/PROJ-1)",
+		},
+		{
+			name:    "not references",
+			line:    "[1.2] prod/website: C#10 and &#39;",
+			project: "prod/website",
+			format:  linksMarkdown,
+			want:    "[1.2] prod/website: C#10 and &#39;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, l.line(tt.line, tt.project, tt.format))
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		msg := message{Sections: []section{newSection("Monday", []timeEntry{{Project: "cert-manager", Description: "#3574"}}, lineOptions{})}}
+		assert.Equal(t, []string{"[.0] cert-manager: [#3574](https://github.com/cert-manager/cert-manager/issues/3574)"}, l.message(msg, linksMarkdown).Sections[0].Lines)
+		assert.Equal(t, msg, l.message(msg, linksNone))
+	})
+}
+
+func Test_newLinker(t *testing.T) {
+	_, err := newLinker(Config{Links: Links{Jira: []JiraLink{{Match: `PROJ-\d+`}}}})
+	require.EqualError(t, err, "links: jira rule #1: match and url are required")
+
+	_, err = newLinker(Config{Links: Links{Jira: []JiraLink{{Match: `(`, URL: "https://example.atlassian.net/browse/"}}}})
+	require.EqualError(t, err, "links: jira rule #1: match: error parsing regexp: missing closing ): `(`")
+}
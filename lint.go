@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+const defaultMaxDuration = 8 * time.Hour
+
+// problem is what 'clockidup lint' reports about a time entry.
+type problem struct {
+	Entry   timeEntry
+	Message string
+}
+
+// lintRule is the compiled version of a LintRule from the config.
+type lintRule struct {
+	name         string
+	project      *regexp.Regexp
+	mustMatch    *regexp.Regexp
+	mustNotMatch *regexp.Regexp
+	message      string
+}
+
+func compileLintRules(rules []LintRule) ([]lintRule, error) {
+	var compiled []lintRule
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.MustMatch == "" && rule.MustNotMatch == "" {
+			return nil, fmt.Errorf("lint rule %s: one of must-match or must-not-match is required", name)
+		}
+
+		c := lintRule{name: name, message: rule.Message}
+		var err error
+		if rule.Project != "" {
+			c.project, err = regexp.Compile(rule.Project)
+			if err != nil {
+				return nil, fmt.Errorf("lint rule %s: project: %s", name, err)
+			}
+		}
+		if rule.MustMatch != "" {
+			c.mustMatch, err = regexp.Compile(rule.MustMatch)
+			if err != nil {
+				return nil, fmt.Errorf("lint rule %s: must-match: %s", name, err)
+			}
+		}
+		if rule.MustNotMatch != "" {
+			c.mustNotMatch, err = regexp.Compile(rule.MustNotMatch)
+			if err != nil {
+				return nil, fmt.Errorf("lint rule %s: must-not-match: %s", name, err)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// lintEntries checks each entry against the team rules. The entries are
+// the raw entries, i.e., before they are merged. An entry that is still
+// running is reported when it started before the day of 'now'.
+func lintEntries(entries []timeEntry, settings clockify.WorkspaceSettings, maxDuration time.Duration, rules []lintRule, now time.Time) []problem {
+	var problems []problem
+	report := func(entry timeEntry, format string, args ...interface{}) {
+		problems = append(problems, problem{Entry: entry, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, entry := range sortedByStart(entries) {
+		if entry.Project == "" {
+			report(entry, "no project")
+		}
+		if strings.TrimSpace(entry.Description) == "" {
+			report(entry, "empty description")
+		}
+		if settings.ForceTasks && entry.Task == "" {
+			report(entry, "no task, but the workspace requires tasks")
+		}
+
+		switch {
+		case entry.End.IsZero() && entry.Start.Before(startOfDay(now)):
+			report(entry, "timer started on %s is still running", entry.Start.Format(layoutISO))
+		case entry.Duration <= 0:
+			report(entry, "zero-length duration")
+		case entry.Duration > maxDuration:
+			report(entry, "duration of %s is longer than %s", formatDuration(entry.Duration), formatDuration(maxDuration))
+		}
+
+		for _, rule := range rules {
+			if rule.project != nil && !rule.project.MatchString(entry.Project) {
+				continue
+			}
+
+			broken := (rule.mustMatch != nil && !rule.mustMatch.MatchString(entry.Description)) ||
+				(rule.mustNotMatch != nil && rule.mustNotMatch.MatchString(entry.Description))
+			if !broken {
+				continue
+			}
+
+			if rule.message != "" {
+				report(entry, "%s (%s)", rule.message, rule.name)
+			} else {
+				report(entry, "breaks the rule %s", rule.name)
+			}
+		}
+	}
+
+	return problems
+}
+
+// parseDayOrRange parses either a single day (e.g. "yesterday") or a range
+// of the form "2021-07-01..2021-07-31". The returned start and end are the
+// beginning of the first day and the end of the last day.
+func parseDayOrRange(arg string) (start, end time.Time, _ error) {
+	parts := strings.SplitN(arg, "..", 2)
+	first, err := parseDay(parts[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	last := first
+	if len(parts) == 2 {
+		last, err = parseDay(parts[1])
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if last.Before(startOfDay(first)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the range '%s' ends before it starts", arg)
+	}
+
+	return startOfDay(first), endOfDay(last), nil
+}
+
+// fetchLintedEntries returns the entries that started between start and end,
+// along with the timer that is still running when it was started before
+// start. Clockify only returns the entries that started in the requested
+// range, which means a timer started yesterday would otherwise never be
+// linted today.
+func fetchLintedEntries(client clockifyClient, now func() time.Time, workspaceName string, start, end time.Time) ([]timeEntry, clockify.Workspace, error) {
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return nil, clockify.Workspace{}, err
+	}
+	userID := workspace.Memberships[0].UserID
+
+	timeEntries, err := client.TimeEntries(workspace.ID, userID, start, end)
+	if err != nil {
+		return nil, clockify.Workspace{}, fmt.Errorf("%s", err)
+	}
+	running, err := client.InProgressTimeEntry(workspace.ID, userID)
+	if err != nil {
+		return nil, clockify.Workspace{}, fmt.Errorf("while fetching the running timer: %s", err)
+	}
+	if running != nil && running.TimeInterval.Start.Before(start) {
+		timeEntries = append(timeEntries, *running)
+	}
+
+	entries, _, err := shortenEntries(client, now, workspace, timeEntries)
+	if err != nil {
+		return nil, clockify.Workspace{}, err
+	}
+	return entries, workspace, nil
+}
+
+func runLint(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	arg := "today"
+	if flags.NArg() > 0 {
+		arg = flags.Arg(0)
+	}
+	start, end, err := parseDayOrRange(arg)
+	if err != nil {
+		return err
+	}
+
+	maxDuration := defaultMaxDuration
+	if conf.Lint.MaxDuration != "" {
+		maxDuration, err = time.ParseDuration(conf.Lint.MaxDuration)
+		if err != nil {
+			return fmt.Errorf("lint: max-duration: %s", err)
+		}
+	}
+	rules, err := compileLintRules(conf.Lint.Rules)
+	if err != nil {
+		return err
+	}
+
+	entries, workspace, err := fetchLintedEntries(client, time.Now, workspaceName, start, end)
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	problems := lintEntries(entries, workspace.WorkspaceSettings, maxDuration, rules, time.Now())
+	for _, p := range problems {
+		fmt.Printf("- %s [%s] %s: %s\n", p.Entry.Start.In(start.Location()).Format("2006-01-02 15:04"), formatHours(p.Entry.Duration), describe(p.Entry), p.Message)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s)", len(problems))
+	}
+	fmt.Printf("No problems found.\n")
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+func Test_lintEntries(t *testing.T) {
+	now := mustParse("2021-07-03T14:00:00Z")
+	valid := timeEntry{Project: "prod/cert-manager", Task: "release", Description: "CM-42: release v1.5", Duration: time.Hour, Start: mustParse("2021-07-03T09:00:00Z"), End: mustParse("2021-07-03T10:00:00Z")}
+	with := func(modify func(e *timeEntry)) timeEntry {
+		e := valid
+		modify(&e)
+		return e
+	}
+
+	noProject := with(func(e *timeEntry) { e.Project = "" })
+	noDescr := with(func(e *timeEntry) { e.Description = " " })
+	noTask := with(func(e *timeEntry) { e.Task = "" })
+	zero := with(func(e *timeEntry) { e.Duration = 0; e.End = e.Start })
+	tooLong := with(func(e *timeEntry) { e.Duration = 9 * time.Hour })
+	runningSinceYesterday := with(func(e *timeEntry) {
+		e.Start = mustParse("2021-07-02T17:00:00Z")
+		e.End = time.Time{}
+		e.Duration = 21 * time.Hour
+	})
+	runningSinceToday := with(func(e *timeEntry) { e.Start = mustParse("2021-07-03T13:00:00Z"); e.End = time.Time{} })
+	noTicket := with(func(e *timeEntry) { e.Description = "release v1.5" })
+	noTicketOtherProject := with(func(e *timeEntry) { e.Project = "admin"; e.Description = "emails" })
+
+	rules, err := compileLintRules([]LintRule{
+		{Name: "jira-ticket", Project: "^prod/", MustMatch: "[A-Z]+-[0-9]+", Message: "no Jira ticket"},
+		{Name: "no-wip", MustNotMatch: "(?i)wip"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		given         []timeEntry
+		givenSettings clockify.WorkspaceSettings
+		want          []problem
+	}{
+		{
+			name:  "valid entry",
+			given: []timeEntry{valid, runningSinceToday},
+		},
+		{
+			name:  "no project",
+			given: []timeEntry{noProject},
+			want:  []problem{{Entry: noProject, Message: "no project"}},
+		},
+		{
+			name:  "empty description",
+			given: []timeEntry{noDescr},
+			want: []problem{
+				{Entry: noDescr, Message: "empty description"},
+				{Entry: noDescr, Message: "no Jira ticket (jira-ticket)"},
+			},
+		},
+		{
+			name:  "missing task is only reported when the workspace forces tasks",
+			given: []timeEntry{noTask},
+		},
+		{
+			name:          "missing task when the workspace forces tasks",
+			given:         []timeEntry{noTask},
+			givenSettings: clockify.WorkspaceSettings{ForceTasks: true},
+			want:          []problem{{Entry: noTask, Message: "no task, but the workspace requires tasks"}},
+		},
+		{
+			name:  "zero and very long durations",
+			given: []timeEntry{tooLong, zero},
+			want: []problem{
+				{Entry: tooLong, Message: "duration of 9h is longer than 8h"},
+				{Entry: zero, Message: "zero-length duration"},
+			},
+		},
+		{
+			name:  "timer running since a previous day",
+			given: []timeEntry{runningSinceYesterday},
+			want:  []problem{{Entry: runningSinceYesterday, Message: "timer started on 2021-07-02 is still running"}},
+		},
+		{
+			name:  "custom rules",
+			given: []timeEntry{noTicketOtherProject, noTicket, with(func(e *timeEntry) { e.Description = "CM-1: WIP" })},
+			want: []problem{
+				{Entry: noTicket, Message: "no Jira ticket (jira-ticket)"},
+				{Entry: with(func(e *timeEntry) { e.Description = "CM-1: WIP" }), Message: "breaks the rule no-wip"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintEntries(tt.given, tt.givenSettings, 8*time.Hour, rules, now)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_compileLintRules(t *testing.T) {
+	_, err := compileLintRules([]LintRule{{Name: "empty"}})
+	require.EqualError(t, err, "lint rule empty: one of must-match or must-not-match is required")
+
+	_, err = compileLintRules([]LintRule{{MustMatch: "("}})
+	require.EqualError(t, err, "lint rule #1: must-match: error parsing regexp: missing closing ): `(`")
+}
+
+func Test_parseDayOrRange(t *testing.T) {
+	start, end, err := parseDayOrRange("2021-07-01..2021-07-31")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 7, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2021, 7, 31, 23, 59, 59, 0, time.Local), end)
+
+	_, _, err = parseDayOrRange("2021-07-31..2021-07-01")
+	require.EqualError(t, err, "the range '2021-07-31..2021-07-01' ends before it starts")
+}
+
+func Test_fetchLintedEntries(t *testing.T) {
+	start, end := mustParse("2021-07-03T00:00:00Z"), mustParse("2021-07-03T23:59:59Z")
+	entry := func(id, start, end string) clockify.TimeEntry {
+		e := clockify.TimeEntry{ID: id, Description: id}
+		e.TimeInterval.Start = mustParse(start)
+		if end != "" {
+			e.TimeInterval.End = mustParse(end)
+		}
+		return e
+	}
+	descriptions := func(entries []timeEntry) []string {
+		var got []string
+		for _, e := range entries {
+			got = append(got, e.Description)
+		}
+		return got
+	}
+	now := func() time.Time { return mustParse("2021-07-03T14:00:00Z") }
+	workspaces := []clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}
+
+	t.Run("the timer running since a previous month is linted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		running := entry("running since June", "2021-06-01T17:00:00Z", "")
+		client.EXPECT().Workspaces().Return(workspaces, nil)
+		client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", start, end).Return([]clockify.TimeEntry{entry("today", "2021-07-03T09:00:00Z", "2021-07-03T10:00:00Z")}, nil)
+		client.EXPECT().InProgressTimeEntry("workspace-1-uid", "user-1-uid").Return(&running, nil)
+		client.EXPECT().Projects("workspace-1-uid").Return(nil, nil)
+
+		got, workspace, err := fetchLintedEntries(client, now, "workspace-1", start, end)
+		require.NoError(t, err)
+		assert.Equal(t, "workspace-1-uid", workspace.ID)
+		assert.Equal(t, []string{"today", "running since June"}, descriptions(got))
+	})
+
+	t.Run("the timer running since today is not linted twice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		client := mocks.NewMockclockifyClient(ctrl)
+		running := entry("running since this morning", "2021-07-03T09:00:00Z", "")
+		client.EXPECT().Workspaces().Return(workspaces, nil)
+		client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", start, end).Return([]clockify.TimeEntry{running}, nil)
+		client.EXPECT().InProgressTimeEntry("workspace-1-uid", "user-1-uid").Return(&running, nil)
+		client.EXPECT().Projects("workspace-1-uid").Return(nil, nil)
+
+		got, _, err := fetchLintedEntries(client, now, "workspace-1", start, end)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"running since this morning"}, descriptions(got))
+	})
+}
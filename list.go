@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+type listOptions struct {
+	output   string // Either "table" or "json".
+	archived bool   // Also list the archived items.
+	project  string // For tasks only.
+}
+
+func runList(client clockifyClient, workspaceName, what string, args []string) error {
+	flags := flag.NewFlagSet(what, flag.ContinueOnError)
+	var opts listOptions
+	flags.StringVar(&opts.output, "output", "table", "Output format, either 'table' or 'json'.")
+	if what == "projects" || what == "clients" || what == "tags" {
+		flags.BoolVar(&opts.archived, "archived", false, "Also list the archived "+what+".")
+	}
+	if what == "tasks" {
+		flags.StringVar(&opts.project, "project", "", "Name of the project for which the tasks are listed.")
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	return list(os.Stdout, client, workspaceName, what, opts)
+}
+
+// list prints the workspaces, projects, tasks, tags or clients, either as a
+// table or as JSON. The JSON output is the one returned by the Clockify API.
+func list(w io.Writer, client clockifyClient, workspaceName, what string, opts listOptions) error {
+	if opts.output != "table" && opts.output != "json" {
+		return fmt.Errorf("--output must be either 'table' or 'json', got '%s'", opts.output)
+	}
+
+	if what == "workspaces" {
+		workspaces, err := client.Workspaces()
+		if err != nil {
+			return fmt.Errorf("while listing workspaces: %w", err)
+		}
+		if opts.output == "json" {
+			return printJSON(w, workspaces)
+		}
+
+		var rows [][]string
+		for _, workspace := range workspaces {
+			current := ""
+			if workspace.Name == workspaceName {
+				current = "*"
+			}
+			rows = append(rows, []string{workspace.Name, workspace.ID, current})
+		}
+		return printTable(w, []string{"NAME", "ID", "CURRENT"}, rows)
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+
+	switch what {
+	case "projects":
+		projects, err := client.Projects(workspace.ID)
+		if err != nil {
+			return fmt.Errorf("while listing projects: %w", err)
+		}
+		selected := []clockify.Project{}
+		for _, project := range projects {
+			if project.Archived && !opts.archived {
+				continue
+			}
+			selected = append(selected, project)
+		}
+		if opts.output == "json" {
+			return printJSON(w, selected)
+		}
+
+		var rows [][]string
+		for _, project := range selected {
+			rows = append(rows, []string{project.Name, project.ID, project.ClientName, yesNo(project.Billable), yesNo(project.Archived)})
+		}
+		return printTable(w, []string{"NAME", "ID", "CLIENT", "BILLABLE", "ARCHIVED"}, rows)
+	case "tasks":
+		if opts.project == "" {
+			return fmt.Errorf("--project is required, e.g. 'clockidup tasks --project prod/cert-manager'")
+		}
+		project, err := projectByName(client, workspace.ID, opts.project)
+		if err != nil {
+			return err
+		}
+		tasks, err := client.Tasks(workspace.ID, project.ID)
+		if err != nil {
+			return fmt.Errorf("while listing tasks: %w", err)
+		}
+		if opts.output == "json" {
+			return printJSON(w, tasks)
+		}
+
+		var rows [][]string
+		for _, task := range tasks {
+			rows = append(rows, []string{task.Name, task.ID, task.Status})
+		}
+		return printTable(w, []string{"NAME", "ID", "STATUS"}, rows)
+	case "tags":
+		tags, err := client.Tags(workspace.ID)
+		if err != nil {
+			return fmt.Errorf("while listing tags: %w", err)
+		}
+		selected := []clockify.Tag{}
+		for _, tag := range tags {
+			if tag.Archived && !opts.archived {
+				continue
+			}
+			selected = append(selected, tag)
+		}
+		if opts.output == "json" {
+			return printJSON(w, selected)
+		}
+
+		var rows [][]string
+		for _, tag := range selected {
+			rows = append(rows, []string{tag.Name, tag.ID, yesNo(tag.Archived)})
+		}
+		return printTable(w, []string{"NAME", "ID", "ARCHIVED"}, rows)
+	case "clients":
+		clients, err := client.Clients(workspace.ID)
+		if err != nil {
+			return fmt.Errorf("while listing clients: %w", err)
+		}
+		selected := []clockify.Client{}
+		for _, c := range clients {
+			if c.Archived && !opts.archived {
+				continue
+			}
+			selected = append(selected, c)
+		}
+		if opts.output == "json" {
+			return printJSON(w, selected)
+		}
+
+		var rows [][]string
+		for _, c := range selected {
+			rows = append(rows, []string{c.Name, c.ID, yesNo(c.Archived)})
+		}
+		return printTable(w, []string{"NAME", "ID", "ARCHIVED"}, rows)
+	default:
+		return fmt.Errorf("programmer mistake: '%s' cannot be listed", what)
+	}
+}
+
+// projectByName returns the project with the exact given name.
+func projectByName(client clockifyClient, workspaceID, name string) (clockify.Project, error) {
+	projects, err := client.Projects(workspaceID)
+	if err != nil {
+		return clockify.Project{}, fmt.Errorf("while listing projects: %w", err)
+	}
+	for _, project := range projects {
+		if project.Name == name {
+			return project, nil
+		}
+	}
+	return clockify.Project{}, fmt.Errorf("no project named '%s', run 'clockidup projects' to see the existing projects", name)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	// The tabwriter pads empty cells in the last column with spaces, which
+	// we don't want to show.
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		_, err = io.WriteString(w, strings.TrimRight(line, " \n")+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/mocks"
+)
+
+func Test_list(t *testing.T) {
+	workspaces := []clockify.Workspace{
+		{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}},
+		{ID: "workspace-2-uid", Name: "workspace-2", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}},
+	}
+	projects := []clockify.Project{
+		{ID: "project-1-uid", Name: "project-1", ClientName: "client-1", Billable: true},
+		{ID: "project-2-uid", Name: "project-2", Archived: true},
+	}
+
+	tests := []struct {
+		name      string
+		givenWhat string
+		givenOpts listOptions
+		givenMock func(m *mocks.MockclockifyClientMockRecorder)
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "workspaces",
+			givenWhat: "workspaces",
+			givenOpts: listOptions{output: "table"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+			},
+			want: heredoc.Doc(`
+				NAME          ID                CURRENT
+				workspace-1   workspace-1-uid   *
+				workspace-2   workspace-2-uid
+			`),
+		},
+		{
+			name:      "archived projects are hidden by default",
+			givenWhat: "projects",
+			givenOpts: listOptions{output: "table"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+			},
+			want: heredoc.Doc(`
+				NAME        ID              CLIENT     BILLABLE   ARCHIVED
+				project-1   project-1-uid   client-1   yes        no
+			`),
+		},
+		{
+			name:      "archived projects are shown with --archived",
+			givenWhat: "projects",
+			givenOpts: listOptions{output: "table", archived: true},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+			},
+			want: heredoc.Doc(`
+				NAME        ID              CLIENT     BILLABLE   ARCHIVED
+				project-1   project-1-uid   client-1   yes        no
+				project-2   project-2-uid              no         yes
+			`),
+		},
+		{
+			name:      "tasks as JSON",
+			givenWhat: "tasks",
+			givenOpts: listOptions{output: "json", project: "project-1"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+				m.Tasks("workspace-1-uid", "project-1-uid").Return([]clockify.Task{{ID: "task-1-uid", Name: "task-1", Status: "ACTIVE"}}, nil)
+			},
+			want: heredoc.Doc(`
+				[
+				  {
+				    "id": "task-1-uid",
+				    "name": "task-1",
+				    "projectId": "",
+				    "assigneeIds": null,
+				    "assigneeId": "",
+				    "estimate": "",
+				    "status": "ACTIVE",
+				    "duration": ""
+				  }
+				]
+			`),
+		},
+		{
+			name:      "tasks of an unknown project",
+			givenWhat: "tasks",
+			givenOpts: listOptions{output: "table", project: "project-3"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Projects("workspace-1-uid").Return(projects, nil)
+			},
+			wantErr: "no project named 'project-3', run 'clockidup projects' to see the existing projects",
+		},
+		{
+			name:      "no tags as JSON",
+			givenWhat: "tags",
+			givenOpts: listOptions{output: "json"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return(workspaces, nil)
+				m.Tags("workspace-1-uid").Return(nil, nil)
+			},
+			want: "[]\n",
+		},
+		{
+			name:      "unknown output",
+			givenWhat: "clients",
+			givenOpts: listOptions{output: "yaml"},
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {},
+			wantErr:   "--output must be either 'table' or 'json', got 'yaml'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mocks.NewMockclockifyClient(ctrl)
+			tt.givenMock(client.EXPECT())
+
+			var buf bytes.Buffer
+			err := list(&buf, client, "workspace-1", tt.givenWhat, tt.givenOpts)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
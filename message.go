@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// message is a standup message before it gets printed or posted. Keeping the
+// structure around (instead of a plain string) lets us render it as plain
+// text, Markdown or Slack's mrkdwn.
+type message struct {
+	Sections []section
+
+	// Footer lines are shown after the sections, separated by an empty
+	// line, e.g. "logged 6.2h / 7.5h (-1.3h)".
+	Footer []string
+}
+
+type section struct {
+	Title string   // For example, "Monday".
+	Lines []string // For example, "[1.2] prod/cert-manager: reviewing PR 3574".
+
+	// Entries[i] is the time entry that Lines[i] was made from. It is used
+	// by --edit to update the time entries that were reworded. Empty when
+	// the lines were not made from time entries.
+	Entries []timeEntry
+}
+
+// newSection returns the section with the standup lines of the entries. The
+// entries are expected to be merged and sorted.
+func newSection(title string, entries []timeEntry, opts lineOptions) section {
+	return section{Title: title, Lines: standupLines(entries, opts), Entries: entries}
+}
+
+// Field returns the value of the custom field 'name' of the entry that the
+// i-th line was made from, or "" when the entry doesn't have this field. It
+// lets the standup template show custom fields, e.g.:
+//
+//  {{ range $i, $line := .Today.Lines }}
+//  - {{ $line }}{{ with $.Today.Field $i "ticket" }} ({{ . }}){{ end }}
+//  {{- end }}
+func (s section) Field(i int, name string) string {
+	if i < 0 || i >= len(s.Entries) {
+		return ""
+	}
+	return s.Entries[i].CustomFields[name]
+}
+
+// The styles used when rendering a message as Markdown or mrkdwn.
+const (
+	styleList = "list" // Each line is a bullet point.
+	styleCode = "code" // The plain text message is put in a code block.
+)
+
+// String returns the message as it is printed by clockidup, e.g.:
+//
+//  Monday:
+//  - [1.2] prod/cert-manager: reviewing PR 3574
+//
+//  logged 6.2h / 7.5h (-1.3h)
+func (m message) String() string {
+	var b strings.Builder
+	for i, s := range m.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s:\n", s.Title)
+		for _, line := range s.Lines {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+	if len(m.Footer) > 0 {
+		b.WriteString("\n")
+		for _, line := range m.Footer {
+			fmt.Fprintf(&b, "%s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// Markdown renders the message for Mattermost and Microsoft Teams. The style
+// is either "list" or "code".
+func (m message) Markdown(style string) string {
+	if style == styleCode {
+		return "```\n" + m.String() + "```"
+	}
+	return m.render("**", "- ", func(text string) string { return text })
+}
+
+// Mrkdwn renders the message using Slack's own flavor of Markdown. Slack
+// does not render "- " as a bullet list, so we use "•" instead. The style is
+// either "list" or "code".
+func (m message) Mrkdwn(style string) string {
+	if style == styleCode {
+		return "```\n" + escapeMrkdwn(m.String()) + "```"
+	}
+	return m.render("*", "• ", escapeMrkdwn)
+}
+
+// Slack uses "<" and ">" for links and mentions, e.g. "<!here>", which means
+// these characters, along with "&", must be escaped in the text we post.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackLink matches the links written by the linker for Slack, e.g.
+// "<https://github.com/a/b/issues/12|#12>".
+var slackLink = regexp.MustCompile(`<https?://[^<>|\s]+\|[^<>]*>`)
+
+// escapeMrkdwn escapes the text for Slack, except for the links added by
+// the linker, which would otherwise show up as plain text.
+func escapeMrkdwn(text string) string {
+	var b strings.Builder
+	pos := 0
+	for _, loc := range slackLink.FindAllStringIndex(text, -1) {
+		b.WriteString(slackEscaper.Replace(text[pos:loc[0]]))
+		b.WriteString(text[loc[0]:loc[1]])
+		pos = loc[1]
+	}
+	b.WriteString(slackEscaper.Replace(text[pos:]))
+	return b.String()
+}
+
+func (m message) render(bold, bullet string, escape func(string) string) string {
+	var b strings.Builder
+	for i, s := range m.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s%s:%s\n", bold, escape(s.Title), bold)
+		for _, line := range s.Lines {
+			fmt.Fprintf(&b, "%s%s\n", bullet, escape(line))
+		}
+	}
+	if len(m.Footer) > 0 {
+		b.WriteString("\n")
+		for _, line := range m.Footer {
+			fmt.Fprintf(&b, "_%s_\n", escape(line))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// dayTitle returns the week day e.g., "Monday" if the date is within a week
+// in the past; otherwise, it returns the date, e.g., "2021-01-28".
+func dayTitle(day time.Time, now time.Time) string {
+	if day.After(now.AddDate(0, 0, -6)) {
+		return day.Format("Monday")
+	}
+	return day.Format("2006-01-02")
+}
+
+// lineOptions changes what the standup lines show.
+type lineOptions struct {
+	// Ongoing suffixes the entries that are still going on with
+	// "(ongoing)".
+	Ongoing bool
+
+	// Start prefixes the lines with the start time of the entry, e.g.
+	// "09:15 [1.2] prod/cert-manager: reviewing PR 3574". It only makes
+	// sense when the entries are not merged.
+	Start bool
+
+	// End adds the end time after the start time, e.g. "09:15–10:30".
+	// The end of the entries still going on is "now".
+	End bool
+
+	// Money suffixes the lines with what the entry earned, e.g.
+	// "(120.50 USD)". The entries that earned nothing are left as is.
+	Money bool
+}
+
+// standupLines turns the entries into the lines of the standup. The entries
+// are expected to be already merged and sorted, see sortEntries.
+func standupLines(entries []timeEntry, opts lineOptions) []string {
+	var lines []string
+	for _, entry := range entries {
+		line := linePrefix(entry) + entry.Description
+		if opts.Start {
+			line = clockPrefix(entry, opts.End) + line
+		}
+		if opts.Ongoing && entry.End.IsZero() {
+			line += " (ongoing)"
+		}
+		if opts.Money {
+			line += moneySuffix(entry)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// linePrefix returns what comes before the description in a standup line,
+// e.g. "[1.2] prod/cert-manager: ".
+func linePrefix(entry timeEntry) string {
+	if entry.Project == "" {
+		entry.Project = "no-project"
+	}
+	return fmt.Sprintf("[%s] %s: ", formatHours(entry.Duration), entry.Project)
+}
+
+// moneySuffix returns what the entry earned, e.g. " (120.50 USD)", or an
+// empty string when the entry earned nothing.
+func moneySuffix(entry timeEntry) string {
+	if entry.Earned == 0 {
+		return ""
+	}
+	return " (" + formatMoney(entry.Earned, entry.Rate.Currency) + ")"
+}
+
+// clockPrefix returns the local start time of the entry, e.g. "09:15 ", and
+// when withEnd is set, the end time too, e.g. "09:15–10:30 ".
+func clockPrefix(entry timeEntry, withEnd bool) string {
+	const clock = "15:04"
+	if !withEnd {
+		return entry.Start.Local().Format(clock) + " "
+	}
+
+	end := "now"
+	if !entry.End.IsZero() {
+		end = entry.End.Local().Format(clock)
+	}
+	return entry.Start.Local().Format(clock) + "–" + end + " "
+}
+
+// standupForDay fetches the time entries of the given day and turns them
+// into a standup message. The rewrite rules, project aliases and hidden
+// projects from the config are applied.
+func standupForDay(client clockifyClient, conf Config, workspaceName string, day time.Time) (message, error) {
+	rw, err := newRewriter(conf)
+	if err != nil {
+		return message{}, err
+	}
+
+	entries, workspace, projects, err := fetchTimeEntries(client, time.Now, workspaceName, startOfDay(day), endOfDay(day))
+	if err != nil {
+		return message{}, fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	// The warnings are about the Clockify project names, which is why
+	// they are computed before the rewriting.
+	warnings := standupWarnings(conf, rw, workspace, projects, entries)
+
+	// The logged hours are computed before --billable is applied since the
+	// target hours are about the time worked, billable or not.
+	logged := totalDuration(entries)
+
+	entries, err = billableOnly(conf, entries)
+	if err != nil {
+		return message{}, err
+	}
+
+	// The descriptions are rewritten before merging so that the entries
+	// that become identical are merged.
+	entries = rw.apply(entries)
+	mode, err := mergeMode()
+	if err != nil {
+		return message{}, err
+	}
+	entries, err = mergeEntries(entries, mode)
+	if err != nil {
+		return message{}, err
+	}
+	entries, err = sortEntries(entries, *sortFlag)
+	if err != nil {
+		return message{}, err
+	}
+
+	msg := message{Sections: []section{newSection(dayTitle(day, time.Now()), entries, lineOptions{Start: mode == mergeNone, End: *timesFlag, Money: *moneyFlag})}}
+
+	target, hasTarget, err := targetForDay(conf, day)
+	if err != nil {
+		return message{}, err
+	}
+	if hasTarget {
+		msg.Footer = append(msg.Footer, formatLogged(logged, target))
+	}
+	if *moneyFlag {
+		msg.Footer = append(msg.Footer, "earned "+formatTotals(totalEarned(entries)))
+	}
+	msg.Footer = append(msg.Footer, warnings...)
+
+	if *editFlag {
+		return reviewMessage(client, workspaceName, msg)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+func Test_standupLines(t *testing.T) {
+	entries := []timeEntry{
+		{Description: "emails", Duration: 30 * time.Minute, Start: mustParse("2021-07-05T09:15:00Z"), End: mustParse("2021-07-05T09:45:00Z")},
+		{Project: "prod/cert-manager", Description: "triaging #2037", Duration: 72 * time.Minute, Start: mustParse("2021-07-05T10:00:00Z")},
+	}
+	start := func(s string) string { return mustParse(s).Local().Format("15:04") }
+
+	assert.Equal(t, []string{
+		"[.5] no-project: emails",
+		"[1.2] prod/cert-manager: triaging #2037",
+	}, standupLines(entries, lineOptions{}))
+
+	assert.Equal(t, []string{
+		start("2021-07-05T09:15:00Z") + " [.5] no-project: emails",
+		start("2021-07-05T10:00:00Z") + " [1.2] prod/cert-manager: triaging #2037 (ongoing)",
+	}, standupLines(entries, lineOptions{Ongoing: true, Start: true}))
+
+	entries[1].Rate, entries[1].Earned = clockify.HourlyRate{Amount: 10000, Currency: "USD"}, 12000
+	assert.Equal(t, []string{
+		"[.5] no-project: emails",
+		"[1.2] prod/cert-manager: triaging #2037 (ongoing) (120.00 USD)",
+	}, standupLines(entries, lineOptions{Ongoing: true, Money: true}))
+}
+
+func Test_clockPrefix(t *testing.T) {
+	clock := func(s string) string { return mustParse(s).Local().Format("15:04") }
+	done := timeEntry{Start: mustParse("2021-07-05T09:15:00Z"), End: mustParse("2021-07-05T10:30:00Z")}
+	running := timeEntry{Start: mustParse("2021-07-05T11:00:00Z")}
+
+	assert.Equal(t, clock("2021-07-05T09:15:00Z")+" ", clockPrefix(done, false))
+	assert.Equal(t, clock("2021-07-05T09:15:00Z")+"–"+clock("2021-07-05T10:30:00Z")+" ", clockPrefix(done, true))
+	assert.Equal(t, clock("2021-07-05T11:00:00Z")+"–now ", clockPrefix(running, true))
+}
+
+func Test_message_Mrkdwn(t *testing.T) {
+	m := message{
+		Sections: []section{{Title: "Monday", Lines: []string{"[1.2] R&D: fixing <br> in the footer"}}},
+		Footer:   []string{"logged 1.2h / 7.5h (-6.3h)"},
+	}
+
+	assert.Equal(t, "*Monday:*\n• [1.2] R&amp;D: fixing &lt;br&gt; in the footer\n\n_logged 1.2h / 7.5h (-6.3h)_", m.Mrkdwn(styleList))
+	assert.Equal(t, "```\nMonday:\n- [1.2] R&amp;D: fixing &lt;br&gt; in the footer\n\nlogged 1.2h / 7.5h (-6.3h)\n```", m.Mrkdwn(styleCode))
+	assert.Equal(t, "**Monday:**\n- [1.2] R&D: fixing <br> in the footer\n\n_logged 1.2h / 7.5h (-6.3h)_", m.Markdown(styleList))
+}
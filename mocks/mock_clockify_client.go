@@ -35,6 +35,125 @@ func (m *MockclockifyClient) EXPECT() *MockclockifyClientMockRecorder {
 	return m.recorder
 }
 
+// Clients mocks base method.
+func (m *MockclockifyClient) Clients(workspaceID string) ([]clockify.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clients", workspaceID)
+	ret0, _ := ret[0].([]clockify.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Clients indicates an expected call of Clients.
+func (mr *MockclockifyClientMockRecorder) Clients(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clients", reflect.TypeOf((*MockclockifyClient)(nil).Clients), workspaceID)
+}
+
+// CreateProject mocks base method.
+func (m *MockclockifyClient) CreateProject(workspaceID, name string) (clockify.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProject", workspaceID, name)
+	ret0, _ := ret[0].(clockify.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProject indicates an expected call of CreateProject.
+func (mr *MockclockifyClientMockRecorder) CreateProject(workspaceID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProject", reflect.TypeOf((*MockclockifyClient)(nil).CreateProject), workspaceID, name)
+}
+
+// CreateTag mocks base method.
+func (m *MockclockifyClient) CreateTag(workspaceID, name string) (clockify.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", workspaceID, name)
+	ret0, _ := ret[0].(clockify.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockclockifyClientMockRecorder) CreateTag(workspaceID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockclockifyClient)(nil).CreateTag), workspaceID, name)
+}
+
+// CreateTask mocks base method.
+func (m *MockclockifyClient) CreateTask(workspaceID, projectID, name string) (clockify.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTask", workspaceID, projectID, name)
+	ret0, _ := ret[0].(clockify.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTask indicates an expected call of CreateTask.
+func (mr *MockclockifyClientMockRecorder) CreateTask(workspaceID, projectID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockclockifyClient)(nil).CreateTask), workspaceID, projectID, name)
+}
+
+// CreateTimeEntry mocks base method.
+func (m *MockclockifyClient) CreateTimeEntry(workspaceID string, create clockify.CreateTimeEntryRequest) (clockify.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTimeEntry", workspaceID, create)
+	ret0, _ := ret[0].(clockify.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTimeEntry indicates an expected call of CreateTimeEntry.
+func (mr *MockclockifyClientMockRecorder) CreateTimeEntry(workspaceID, create interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTimeEntry", reflect.TypeOf((*MockclockifyClient)(nil).CreateTimeEntry), workspaceID, create)
+}
+
+// CustomFields mocks base method.
+func (m *MockclockifyClient) CustomFields(workspaceID string) ([]clockify.CustomField, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CustomFields", workspaceID)
+	ret0, _ := ret[0].([]clockify.CustomField)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CustomFields indicates an expected call of CustomFields.
+func (mr *MockclockifyClientMockRecorder) CustomFields(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CustomFields", reflect.TypeOf((*MockclockifyClient)(nil).CustomFields), workspaceID)
+}
+
+// DeleteTimeEntry mocks base method.
+func (m *MockclockifyClient) DeleteTimeEntry(workspaceID, timeEntryID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTimeEntry", workspaceID, timeEntryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTimeEntry indicates an expected call of DeleteTimeEntry.
+func (mr *MockclockifyClientMockRecorder) DeleteTimeEntry(workspaceID, timeEntryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTimeEntry", reflect.TypeOf((*MockclockifyClient)(nil).DeleteTimeEntry), workspaceID, timeEntryID)
+}
+
+// InProgressTimeEntry mocks base method.
+func (m *MockclockifyClient) InProgressTimeEntry(workspaceID, userID string) (*clockify.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InProgressTimeEntry", workspaceID, userID)
+	ret0, _ := ret[0].(*clockify.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InProgressTimeEntry indicates an expected call of InProgressTimeEntry.
+func (mr *MockclockifyClientMockRecorder) InProgressTimeEntry(workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InProgressTimeEntry", reflect.TypeOf((*MockclockifyClient)(nil).InProgressTimeEntry), workspaceID, userID)
+}
+
 // Projects mocks base method.
 func (m *MockclockifyClient) Projects(workspaceID string) ([]clockify.Project, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +169,21 @@ func (mr *MockclockifyClientMockRecorder) Projects(workspaceID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Projects", reflect.TypeOf((*MockclockifyClient)(nil).Projects), workspaceID)
 }
 
+// Tags mocks base method.
+func (m *MockclockifyClient) Tags(workspaceID string) ([]clockify.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tags", workspaceID)
+	ret0, _ := ret[0].([]clockify.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tags indicates an expected call of Tags.
+func (mr *MockclockifyClientMockRecorder) Tags(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tags", reflect.TypeOf((*MockclockifyClient)(nil).Tags), workspaceID)
+}
+
 // Task mocks base method.
 func (m *MockclockifyClient) Task(workspaceID, projectID, taskID string) (clockify.Task, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +199,21 @@ func (mr *MockclockifyClientMockRecorder) Task(workspaceID, projectID, taskID in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Task", reflect.TypeOf((*MockclockifyClient)(nil).Task), workspaceID, projectID, taskID)
 }
 
+// Tasks mocks base method.
+func (m *MockclockifyClient) Tasks(workspaceID, projectID string) ([]clockify.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tasks", workspaceID, projectID)
+	ret0, _ := ret[0].([]clockify.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tasks indicates an expected call of Tasks.
+func (mr *MockclockifyClientMockRecorder) Tasks(workspaceID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tasks", reflect.TypeOf((*MockclockifyClient)(nil).Tasks), workspaceID, projectID)
+}
+
 // TimeEntries mocks base method.
 func (m *MockclockifyClient) TimeEntries(workspaceID, userID string, start, end time.Time) ([]clockify.TimeEntry, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +229,36 @@ func (mr *MockclockifyClientMockRecorder) TimeEntries(workspaceID, userID, start
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeEntries", reflect.TypeOf((*MockclockifyClient)(nil).TimeEntries), workspaceID, userID, start, end)
 }
 
+// TimeEntry mocks base method.
+func (m *MockclockifyClient) TimeEntry(workspaceID, timeEntryID string) (clockify.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeEntry", workspaceID, timeEntryID)
+	ret0, _ := ret[0].(clockify.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TimeEntry indicates an expected call of TimeEntry.
+func (mr *MockclockifyClientMockRecorder) TimeEntry(workspaceID, timeEntryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeEntry", reflect.TypeOf((*MockclockifyClient)(nil).TimeEntry), workspaceID, timeEntryID)
+}
+
+// UpdateTimeEntry mocks base method.
+func (m *MockclockifyClient) UpdateTimeEntry(workspaceID, timeEntryID string, update clockify.UpdateTimeEntryRequest) (clockify.TimeEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTimeEntry", workspaceID, timeEntryID, update)
+	ret0, _ := ret[0].(clockify.TimeEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTimeEntry indicates an expected call of UpdateTimeEntry.
+func (mr *MockclockifyClientMockRecorder) UpdateTimeEntry(workspaceID, timeEntryID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimeEntry", reflect.TypeOf((*MockclockifyClient)(nil).UpdateTimeEntry), workspaceID, timeEntryID, update)
+}
+
 // Workspaces mocks base method.
 func (m *MockclockifyClient) Workspaces() ([]clockify.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+// hourlyRate returns the rate at which the user's time on the project is
+// billed. Like in Clockify, the user's rate on the project comes first, then
+// the project's rate, then the user's rate in the workspace, and finally the
+// workspace's rate. The project is nil for the entries with no project. A
+// zero rate is returned when no rate is set.
+func hourlyRate(workspace clockify.Workspace, project *clockify.Project, userID string) clockify.HourlyRate {
+	var rates []clockify.HourlyRate
+	if project != nil {
+		rates = append(rates, memberRate(project.Memberships, userID), project.HourlyRate)
+	}
+	rates = append(rates, memberRate(workspace.Memberships, userID), workspace.HourlyRate)
+
+	for _, rate := range rates {
+		if rate.Amount == 0 {
+			continue
+		}
+		// The rates of the members sometimes come without a currency.
+		if rate.Currency == "" {
+			rate.Currency = workspace.HourlyRate.Currency
+		}
+		return rate
+	}
+	return clockify.HourlyRate{}
+}
+
+func memberRate(memberships []clockify.Memberships, userID string) clockify.HourlyRate {
+	for _, m := range memberships {
+		if m.UserID == userID && m.HourlyRate != nil {
+			return *m.HourlyRate
+		}
+	}
+	return clockify.HourlyRate{}
+}
+
+// earned returns what the duration is worth at the given rate, in cents.
+func earned(d time.Duration, rate clockify.HourlyRate) int64 {
+	return int64(math.Round(float64(rate.Amount) * d.Hours()))
+}
+
+// formatMoney turns an amount in cents into e.g. "1234.50 USD".
+func formatMoney(cents int64, currency string) string {
+	s := fmt.Sprintf("%d.%02d", cents/100, cents%100)
+	if currency == "" {
+		return s
+	}
+	return s + " " + currency
+}
+
+// totalEarned adds up what the entries earned, per currency.
+func totalEarned(entries []timeEntry) map[string]int64 {
+	totals := make(map[string]int64)
+	for _, entry := range entries {
+		if entry.Earned != 0 {
+			totals[entry.Rate.Currency] += entry.Earned
+		}
+	}
+	return totals
+}
+
+// formatTotals returns e.g. "1234.50 USD + 80.00 EUR". The currencies are
+// sorted alphabetically.
+func formatTotals(totals map[string]int64) string {
+	var currencies []string
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var parts []string
+	for _, currency := range currencies {
+		parts = append(parts, formatMoney(totals[currency], currency))
+	}
+	if len(parts) == 0 {
+		return formatMoney(0, "")
+	}
+	return strings.Join(parts, " + ")
+}
+
+// earning is one line of 'clockidup earnings'.
+type earning struct {
+	Project  string
+	Billable time.Duration
+	Rate     clockify.HourlyRate
+	Earned   int64 // In cents.
+}
+
+// computeEarnings adds up the billable entries per project. The projects
+// that earned the most come first.
+func computeEarnings(entries []timeEntry) []earning {
+	var earnings []earning
+	index := make(map[string]int)
+	for _, entry := range entries {
+		if !entry.Billable {
+			continue
+		}
+		project := entry.Project
+		if project == "" {
+			project = "no-project"
+		}
+		i, ok := index[project]
+		if !ok {
+			i = len(earnings)
+			index[project] = i
+			earnings = append(earnings, earning{Project: project, Rate: entry.Rate})
+		}
+		earnings[i].Billable += entry.Duration
+		earnings[i].Earned += entry.Earned
+	}
+
+	sort.SliceStable(earnings, func(i, j int) bool {
+		if earnings[i].Earned != earnings[j].Earned {
+			return earnings[i].Earned > earnings[j].Earned
+		}
+		return earnings[i].Project < earnings[j].Project
+	})
+	return earnings
+}
+
+// earningsRows returns the rows of the table shown by 'clockidup earnings'.
+// The last row is the total.
+func earningsRows(entries []timeEntry) [][]string {
+	var rows [][]string
+	var billable time.Duration
+	for _, e := range computeEarnings(entries) {
+		rate := "-"
+		if e.Rate.Amount != 0 {
+			rate = formatMoney(int64(e.Rate.Amount), e.Rate.Currency) + "/h"
+		}
+		rows = append(rows, []string{e.Project, formatDuration(e.Billable), rate, formatMoney(e.Earned, e.Rate.Currency)})
+		billable += e.Billable
+	}
+	return append(rows, []string{"TOTAL", formatDuration(billable), "", formatTotals(totalEarned(entries))})
+}
+
+func runEarnings(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("earnings", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required, e.g. 'clockidup earnings --from 2021-07-01 --to 2021-07-31'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+
+	entries, err := timeEntriesForRange(client, time.Now, workspaceName, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+
+	return printTable(os.Stdout, []string{"PROJECT", "BILLABLE", "RATE", "EARNED"}, earningsRows(entries))
+}
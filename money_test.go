@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+func Test_hourlyRate(t *testing.T) {
+	usd := func(amount int) clockify.HourlyRate { return clockify.HourlyRate{Amount: amount, Currency: "USD"} }
+	member := func(userID string, rate *clockify.HourlyRate) []clockify.Memberships {
+		return []clockify.Memberships{{UserID: userID, HourlyRate: rate}}
+	}
+
+	tests := []struct {
+		name      string
+		workspace clockify.Workspace
+		project   *clockify.Project
+		want      clockify.HourlyRate
+	}{
+		{
+			name:      "no rate at all",
+			workspace: clockify.Workspace{HourlyRate: clockify.HourlyRate{Currency: "USD"}, Memberships: member("user-1", nil)},
+			project:   &clockify.Project{HourlyRate: clockify.HourlyRate{Currency: "USD"}},
+			want:      clockify.HourlyRate{},
+		},
+		{
+			name:      "the workspace's rate",
+			workspace: clockify.Workspace{HourlyRate: usd(5000), Memberships: member("user-1", nil)},
+			project:   &clockify.Project{HourlyRate: clockify.HourlyRate{Currency: "USD"}},
+			want:      usd(5000),
+		},
+		{
+			name:      "the user's rate in the workspace comes before the workspace's rate",
+			workspace: clockify.Workspace{HourlyRate: usd(5000), Memberships: member("user-1", &clockify.HourlyRate{Amount: 6000})},
+			project:   &clockify.Project{},
+			want:      usd(6000),
+		},
+		{
+			name:      "the project's rate comes before the workspace's rates",
+			workspace: clockify.Workspace{HourlyRate: usd(5000), Memberships: member("user-1", &clockify.HourlyRate{Amount: 6000, Currency: "USD"})},
+			project:   &clockify.Project{HourlyRate: usd(8000)},
+			want:      usd(8000),
+		},
+		{
+			name:      "the user's rate on the project comes first",
+			workspace: clockify.Workspace{HourlyRate: usd(5000)},
+			project:   &clockify.Project{HourlyRate: usd(8000), Memberships: member("user-1", &clockify.HourlyRate{Amount: 9500, Currency: "EUR"})},
+			want:      clockify.HourlyRate{Amount: 9500, Currency: "EUR"},
+		},
+		{
+			name:      "the rates of the other users are ignored",
+			workspace: clockify.Workspace{HourlyRate: usd(5000)},
+			project:   &clockify.Project{Memberships: member("user-2", &clockify.HourlyRate{Amount: 9500, Currency: "USD"})},
+			want:      usd(5000),
+		},
+		{
+			name:      "no project",
+			workspace: clockify.Workspace{HourlyRate: usd(5000)},
+			want:      usd(5000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hourlyRate(tt.workspace, tt.project, "user-1"))
+		})
+	}
+}
+
+func Test_formatMoney(t *testing.T) {
+	assert.Equal(t, "0.00", formatMoney(0, ""))
+	assert.Equal(t, "0.05 USD", formatMoney(5, "USD"))
+	assert.Equal(t, "1234.50 EUR", formatMoney(123450, "EUR"))
+	assert.Equal(t, "450.00 USD", formatMoney(earned(4*time.Hour+30*time.Minute, clockify.HourlyRate{Amount: 10000}), "USD"))
+}
+
+func Test_earningsRows(t *testing.T) {
+	usd := clockify.HourlyRate{Amount: 10000, Currency: "USD"}
+	eur := clockify.HourlyRate{Amount: 8000, Currency: "EUR"}
+	entries := []timeEntry{
+		{Project: "prod/cert-manager", Billable: true, Duration: 2 * time.Hour, Rate: usd, Earned: 20000},
+		{Project: "admin", Duration: time.Hour, Rate: usd},
+		{Project: "support", Billable: true, Duration: 90 * time.Minute, Rate: eur, Earned: 12000},
+		{Project: "prod/cert-manager", Billable: true, Duration: time.Hour, Rate: usd, Earned: 10000},
+		{Billable: true, Duration: 30 * time.Minute},
+	}
+
+	assert.Equal(t, [][]string{
+		{"prod/cert-manager", "3h", "100.00 USD/h", "300.00 USD"},
+		{"support", "1h30", "80.00 EUR/h", "120.00 EUR"},
+		{"no-project", "30m", "-", "0.00"},
+		{"TOTAL", "5h", "", "120.00 EUR + 300.00 USD"},
+	}, earningsRows(entries))
+}
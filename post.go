@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// sink is where a standup message can be posted to, e.g. a Slack channel.
+type sink interface {
+	Name() string
+
+	// Render returns the text that Post sends, which is useful for
+	// --dry-run.
+	Render(msg message) string
+	Post(msg message) error
+
+	// LinkFormat is the format of the links that the sink renders, i.e.
+	// "markdown" or "slack".
+	LinkFormat() string
+}
+
+// sinksFromConfig returns the sinks configured under 'post:' in the config.
+// When 'only' is not empty, only the sinks with the given names are
+// returned.
+func sinksFromConfig(conf Post, only []string) ([]sink, error) {
+	style := conf.Format
+	switch style {
+	case "":
+		style = styleCode
+	case styleCode, styleList:
+	default:
+		return nil, fmt.Errorf("post: format must be either 'code' or 'list', got '%s'", style)
+	}
+
+	var sinks []sink
+	if conf.Slack != nil {
+		switch {
+		case conf.Slack.WebhookURL != "" && conf.Slack.Token != "":
+			return nil, fmt.Errorf("post: slack: only one of webhook-url or token can be set")
+		case conf.Slack.WebhookURL != "":
+			sinks = append(sinks, &slackWebhook{webhookURL: conf.Slack.WebhookURL, style: style, client: http.DefaultClient})
+		case conf.Slack.Token != "":
+			if conf.Slack.Channel == "" {
+				return nil, fmt.Errorf("post: slack: channel is required when token is set")
+			}
+			sinks = append(sinks, &slackAPI{token: conf.Slack.Token, channel: conf.Slack.Channel, style: style, server: "https://slack.com/api", client: http.DefaultClient})
+		default:
+			return nil, fmt.Errorf("post: slack: one of webhook-url or token is required")
+		}
+	}
+	if conf.Mattermost != nil {
+		if conf.Mattermost.WebhookURL == "" {
+			return nil, fmt.Errorf("post: mattermost: webhook-url is required")
+		}
+		sinks = append(sinks, &mattermostWebhook{webhookURL: conf.Mattermost.WebhookURL, channel: conf.Mattermost.Channel, style: style, client: http.DefaultClient})
+	}
+	if conf.Teams != nil {
+		if conf.Teams.WebhookURL == "" {
+			return nil, fmt.Errorf("post: teams: webhook-url is required")
+		}
+		sinks = append(sinks, &teamsWebhook{webhookURL: conf.Teams.WebhookURL, style: style, client: http.DefaultClient})
+	}
+
+	if len(only) == 0 {
+		return sinks, nil
+	}
+
+	var selected []sink
+	for _, name := range only {
+		found := false
+		for _, s := range sinks {
+			if s.Name() == name {
+				selected = append(selected, s)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("--to: '%s' is not configured under 'post:' in ~/%s", name, confPath)
+		}
+	}
+	return selected, nil
+}
+
+// slackWebhook posts using a Slack incoming webhook. The channel is the one
+// chosen when creating the webhook.
+type slackWebhook struct {
+	webhookURL string
+	style      string
+	client     *http.Client
+}
+
+func (s *slackWebhook) Name() string { return "slack" }
+
+func (s *slackWebhook) Render(msg message) string { return msg.Mrkdwn(s.style) }
+
+func (s *slackWebhook) LinkFormat() string { return linksSlack }
+
+func (s *slackWebhook) Post(msg message) error {
+	return postJSON(s.client, s.webhookURL, map[string]string{"text": s.Render(msg)})
+}
+
+// slackAPI posts using the Slack Web API. Unlike the incoming webhooks, the
+// message is posted as the user who owns the token.
+type slackAPI struct {
+	token   string
+	channel string // Name of the channel, e.g. "stand-ups".
+	style   string
+	server  string // Defaults to https://slack.com/api.
+	client  *http.Client
+}
+
+func (s *slackAPI) Name() string { return "slack" }
+
+func (s *slackAPI) Render(msg message) string { return msg.Mrkdwn(s.style) }
+
+func (s *slackAPI) LinkFormat() string { return linksSlack }
+
+// All the Slack Web API responses have this shape.
+type slackResponse struct {
+	OK       bool   `json:"ok"`
+	Error    string `json:"error"`
+	Channels []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channels"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+func (s *slackAPI) Post(msg message) error {
+	channelID, err := s.channelID()
+	if err != nil {
+		return err
+	}
+
+	var resp slackResponse
+	err = s.call("chat.postMessage", map[string]string{"channel": channelID, "text": s.Render(msg)}, &resp)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// channelID looks up the ID of the channel by going through all the pages
+// returned by conversations.list.
+func (s *slackAPI) channelID() (string, error) {
+	cursor := ""
+	for {
+		var resp slackResponse
+		err := s.call("conversations.list", map[string]string{
+			"types":  "public_channel,private_channel",
+			"limit":  "1000",
+			"cursor": cursor,
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+
+		for _, channel := range resp.Channels {
+			if channel.Name == strings.TrimPrefix(s.channel, "#") {
+				return channel.ID, nil
+			}
+		}
+
+		cursor = resp.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return "", fmt.Errorf("slack: channel '%s' not found", s.channel)
+		}
+	}
+}
+
+func (s *slackAPI) call(method string, params map[string]string, resp *slackResponse) error {
+	form := url.Values{}
+	for k, v := range params {
+		if v == "" {
+			continue
+		}
+		form.Set(k, v)
+	}
+
+	req, err := http.NewRequest("POST", s.server+"/"+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("slack: creating HTTP request for %s: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	httpResp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack: while calling %s: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	bytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("slack: while reading the response of %s: %w", method, err)
+	}
+	if httpResp.StatusCode != 200 {
+		return fmt.Errorf("slack: %s: %d %s: %s", method, httpResp.StatusCode, http.StatusText(httpResp.StatusCode), bytes)
+	}
+
+	err = json.Unmarshal(bytes, resp)
+	if err != nil {
+		return fmt.Errorf("slack: while parsing the response of %s: %w", method, err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("slack: %s: %s", method, resp.Error)
+	}
+
+	return nil
+}
+
+type mattermostWebhook struct {
+	webhookURL string
+	channel    string // Optional, overrides the webhook's channel.
+	style      string
+	client     *http.Client
+}
+
+func (s *mattermostWebhook) Name() string { return "mattermost" }
+
+func (s *mattermostWebhook) Render(msg message) string { return msg.Markdown(s.style) }
+
+func (s *mattermostWebhook) LinkFormat() string { return linksMarkdown }
+
+func (s *mattermostWebhook) Post(msg message) error {
+	payload := map[string]string{"text": s.Render(msg)}
+	if s.channel != "" {
+		payload["channel"] = s.channel
+	}
+	return postJSON(s.client, s.webhookURL, payload)
+}
+
+type teamsWebhook struct {
+	webhookURL string
+	style      string
+	client     *http.Client
+}
+
+func (s *teamsWebhook) Name() string { return "teams" }
+
+func (s *teamsWebhook) Render(msg message) string { return msg.Markdown(s.style) }
+
+func (s *teamsWebhook) LinkFormat() string { return linksMarkdown }
+
+func (s *teamsWebhook) Post(msg message) error {
+	return postJSON(s.client, s.webhookURL, map[string]string{"text": s.Render(msg)})
+}
+
+// postJSON sends the payload as JSON and expects a 2xx response.
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("while encoding the payload to JSON: %w", err)
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("while posting to the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("the webhook returned %d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), respBody)
+	}
+
+	return nil
+}
+
+func runPost(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("post", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Print what would be posted instead of posting it.")
+	to := flags.String("to", "", "Comma-separated list of the sinks to post to, e.g. 'slack,teams'. Defaults to all the sinks configured under 'post:'.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	var only []string
+	if *to != "" {
+		only = strings.Split(*to, ",")
+	}
+	sinks, err := sinksFromConfig(conf.Post, only)
+	if err != nil {
+		return err
+	}
+	if len(sinks) == 0 {
+		return fmt.Errorf("nowhere to post to, add 'slack', 'mattermost' or 'teams' under 'post:' in ~/%s", confPath)
+	}
+
+	dayArg := "today"
+	if flags.NArg() > 0 {
+		dayArg = flags.Arg(0)
+	}
+	day, err := parseDay(dayArg)
+	if err != nil {
+		return err
+	}
+
+	l, err := newLinker(conf)
+	if err != nil {
+		return err
+	}
+	format, err := linkFormat(conf.Links)
+	if err != nil {
+		return err
+	}
+
+	msg, err := standupForDay(client, conf, workspaceName, day)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sinks {
+		// Each sink has its own link format. Links are not rendered in
+		// code blocks, so they are only added with the "list" format.
+		msg := msg
+		if format != linksNone && conf.Post.Format == styleList {
+			msg = l.message(msg, s.LinkFormat())
+		}
+
+		if *dryRun {
+			fmt.Printf("Would post to %s:\n%s\n", s.Name(), s.Render(msg))
+			continue
+		}
+
+		start := time.Now()
+		err := s.Post(msg)
+		if err != nil {
+			return fmt.Errorf("while posting to %s: %w", s.Name(), err)
+		}
+		logutil.Debugf("posted to %s in %s", s.Name(), time.Since(start))
+		logutil.Infof("posted to %s", s.Name())
+	}
+
+	return nil
+}
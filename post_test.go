@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMessage = message{
+	Sections: []section{{
+		Title: "Monday",
+		Lines: []string{
+			"[.8] prod/cert-manager: cert-manager standup",
+			"[1.2] prod/cert-manager: reviewing PR 3574",
+		},
+	}},
+	Footer: []string{"logged 2.0h / 7.5h (-5.5h)"},
+}
+
+func Test_message(t *testing.T) {
+	t.Run("plain text", func(t *testing.T) {
+		assert.Equal(t, heredoc.Doc(`
+			Monday:
+			- [.8] prod/cert-manager: cert-manager standup
+			- [1.2] prod/cert-manager: reviewing PR 3574
+
+			logged 2.0h / 7.5h (-5.5h)
+		`), testMessage.String())
+	})
+
+	t.Run("Markdown list", func(t *testing.T) {
+		assert.Equal(t, heredoc.Doc(`
+			**Monday:**
+			- [.8] prod/cert-manager: cert-manager standup
+			- [1.2] prod/cert-manager: reviewing PR 3574
+
+			_logged 2.0h / 7.5h (-5.5h)_`), testMessage.Markdown(styleList))
+	})
+
+	t.Run("mrkdwn list", func(t *testing.T) {
+		assert.Equal(t, heredoc.Doc(`
+			*Monday:*
+			• [.8] prod/cert-manager: cert-manager standup
+			• [1.2] prod/cert-manager: reviewing PR 3574
+
+			_logged 2.0h / 7.5h (-5.5h)_`), testMessage.Mrkdwn(styleList))
+	})
+
+	t.Run("code block", func(t *testing.T) {
+		assert.Equal(t, "```\n"+testMessage.String()+"```", testMessage.Mrkdwn(styleCode))
+		assert.Equal(t, "```\n"+testMessage.String()+"```", testMessage.Markdown(styleCode))
+	})
+}
+
+// withWebhook returns the URL of a local stand-in for a webhook. The JSON
+// payloads received are appended to 'got'.
+func withWebhook(t *testing.T, got *[]map[string]string) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		*got = append(*got, payload)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func Test_webhooks(t *testing.T) {
+	t.Run("slack webhook", func(t *testing.T) {
+		var got []map[string]string
+		s := &slackWebhook{webhookURL: withWebhook(t, &got), style: styleList, client: http.DefaultClient}
+
+		require.NoError(t, s.Post(testMessage))
+		assert.Equal(t, []map[string]string{{"text": testMessage.Mrkdwn(styleList)}}, got)
+	})
+
+	t.Run("slack webhook with links and characters to escape", func(t *testing.T) {
+		l, err := newLinker(Config{Links: Links{Projects: map[string]string{"a/b": "github.com/a/b"}}})
+		require.NoError(t, err)
+		msg := l.message(message{Sections: []section{newSection("Monday", []timeEntry{{Project: "a/b", Description: "fix #12 & <x>", Duration: time.Hour}}, lineOptions{})}}, linksSlack)
+
+		var got []map[string]string
+		s := &slackWebhook{webhookURL: withWebhook(t, &got), style: styleList, client: http.DefaultClient}
+
+		require.NoError(t, s.Post(msg))
+		assert.Equal(t, []map[string]string{{"text": "*Monday:*\n• [1.0] a/b: fix <https://github.com/a/b/issues/12|#12> &amp; &lt;x&gt;"}}, got)
+	})
+
+	t.Run("mattermost webhook with a channel", func(t *testing.T) {
+		var got []map[string]string
+		s := &mattermostWebhook{webhookURL: withWebhook(t, &got), channel: "town-square", style: styleCode, client: http.DefaultClient}
+
+		require.NoError(t, s.Post(testMessage))
+		assert.Equal(t, []map[string]string{{"text": testMessage.Markdown(styleCode), "channel": "town-square"}}, got)
+	})
+
+	t.Run("teams webhook", func(t *testing.T) {
+		var got []map[string]string
+		s := &teamsWebhook{webhookURL: withWebhook(t, &got), style: styleList, client: http.DefaultClient}
+
+		require.NoError(t, s.Post(testMessage))
+		assert.Equal(t, []map[string]string{{"text": testMessage.Markdown(styleList)}}, got)
+	})
+
+	t.Run("webhook returns an error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(404)
+			_, _ = w.Write([]byte("no_service"))
+		}))
+		defer server.Close()
+		s := &slackWebhook{webhookURL: server.URL, style: styleList, client: http.DefaultClient}
+
+		err := s.Post(testMessage)
+		require.EqualError(t, err, "the webhook returned 404 Not Found: no_service")
+	})
+}
+
+func Test_slackAPI(t *testing.T) {
+	// The channel we are looking for is on the second page returned by
+	// conversations.list.
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxp-token", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+
+		switch r.URL.Path {
+		case "/conversations.list":
+			if r.Form.Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{"ok":true,"channels":[{"id":"C1","name":"general"}],"response_metadata":{"next_cursor":"page-2"}}`))
+				return
+			}
+			assert.Equal(t, "page-2", r.Form.Get("cursor"))
+			_, _ = w.Write([]byte(`{"ok":true,"channels":[{"id":"C2","name":"stand-ups"}],"response_metadata":{"next_cursor":""}}`))
+		case "/chat.postMessage":
+			assert.Equal(t, "C2", r.Form.Get("channel"))
+			posted = append(posted, r.Form.Get("text"))
+			_, _ = w.Write([]byte(`{"ok":true}`))
+		default:
+			body, _ := ioutil.ReadAll(r.Body)
+			t.Errorf("unexpected call to %s: %s", r.URL.Path, body)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("the channel is looked up and the message is posted", func(t *testing.T) {
+		s := &slackAPI{token: "xoxp-token", channel: "#stand-ups", style: styleCode, server: server.URL, client: http.DefaultClient}
+
+		require.NoError(t, s.Post(testMessage))
+		assert.Equal(t, []string{testMessage.Mrkdwn(styleCode)}, posted)
+	})
+
+	t.Run("the channel does not exist", func(t *testing.T) {
+		s := &slackAPI{token: "xoxp-token", channel: "random", style: styleCode, server: server.URL, client: http.DefaultClient}
+
+		err := s.Post(testMessage)
+		require.EqualError(t, err, "slack: channel 'random' not found")
+	})
+}
+
+func Test_sinksFromConfig(t *testing.T) {
+	t.Run("all the sinks", func(t *testing.T) {
+		got, err := sinksFromConfig(Post{
+			Slack:      &Slack{Token: "xoxp-token", Channel: "stand-ups"},
+			Mattermost: &Mattermost{WebhookURL: "https://mattermost.example.com/hooks/xxx"},
+			Teams:      &Teams{WebhookURL: "https://example.webhook.office.com/xxx"},
+		}, nil)
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, "slack", got[0].Name())
+		assert.Equal(t, "mattermost", got[1].Name())
+		assert.Equal(t, "teams", got[2].Name())
+	})
+
+	t.Run("only some sinks", func(t *testing.T) {
+		got, err := sinksFromConfig(Post{
+			Slack: &Slack{WebhookURL: "https://hooks.slack.com/services/xxx"},
+			Teams: &Teams{WebhookURL: "https://example.webhook.office.com/xxx"},
+		}, []string{"teams"})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "teams", got[0].Name())
+	})
+
+	t.Run("sink not configured", func(t *testing.T) {
+		_, err := sinksFromConfig(Post{}, []string{"teams"})
+		require.EqualError(t, err, "--to: 'teams' is not configured under 'post:' in ~/.config/clockidup.yml")
+	})
+
+	t.Run("slack token without channel", func(t *testing.T) {
+		_, err := sinksFromConfig(Post{Slack: &Slack{Token: "xoxp-token"}}, nil)
+		require.EqualError(t, err, "post: slack: channel is required when token is set")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := sinksFromConfig(Post{Format: "table"}, nil)
+		require.EqualError(t, err, "post: format must be either 'code' or 'list', got 'table'")
+	})
+}
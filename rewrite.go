@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// rewriter is the compiled version of the 'rewrite', 'project-aliases' and
+// 'hide-projects' settings. It is applied to the entries before they are
+// merged so that entries that only differ before being rewritten get merged.
+type rewriter struct {
+	rules   []rewriteRule
+	aliases map[string]string
+	hidden  map[string]bool
+}
+
+type rewriteRule struct {
+	match   *regexp.Regexp
+	replace string
+}
+
+func newRewriter(conf Config) (rewriter, error) {
+	r := rewriter{aliases: conf.ProjectAliases, hidden: make(map[string]bool)}
+	for i, rule := range conf.Rewrite {
+		if rule.Match == "" {
+			return rewriter{}, fmt.Errorf("rewrite rule #%d: match is required", i+1)
+		}
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return rewriter{}, fmt.Errorf("rewrite rule #%d: match: %s", i+1, err)
+		}
+		r.rules = append(r.rules, rewriteRule{match: re, replace: rule.Replace})
+	}
+	for _, project := range conf.HideProjects {
+		r.hidden[project] = true
+	}
+	return r, nil
+}
+
+// apply leaves out the entries of the hidden projects, rewrites the
+// descriptions and replaces the project names with their aliases.
+func (r rewriter) apply(entries []timeEntry) []timeEntry {
+	var rewritten []timeEntry
+	for _, entry := range entries {
+		if r.hidden[entry.Project] {
+			continue
+		}
+
+		for _, rule := range r.rules {
+			entry.Description = rule.match.ReplaceAllString(entry.Description, rule.replace)
+		}
+		entry.Description = strings.TrimSpace(entry.Description)
+
+		if alias, ok := r.aliases[entry.Project]; ok {
+			entry.Project = alias
+		}
+
+		rewritten = append(rewritten, entry)
+	}
+	return rewritten
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rewriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    Config
+		given   []timeEntry
+		want    []timeEntry
+		wantErr string
+	}{
+		{
+			name:  "nothing configured",
+			given: []timeEntry{{Project: "prod/cert-manager", Description: "reviewing PR 3574"}},
+			want:  []timeEntry{{Project: "prod/cert-manager", Description: "reviewing PR 3574"}},
+		},
+		{
+			name: "rules are applied in order",
+			conf: Config{Rewrite: []RewriteRule{
+				{Match: `\bPR (\d+)`, Replace: "#$1"},
+				{Match: `#(\d+)`, Replace: "cert-manager#$1"},
+				{Match: `(?i)\s*\bfalcon\b`, Replace: ""},
+			}},
+			given: []timeEntry{{Description: "reviewing PR 3574 Falcon"}},
+			want:  []timeEntry{{Description: "reviewing cert-manager#3574"}},
+		},
+		{
+			name: "projects are aliased and hidden using their Clockify names",
+			conf: Config{
+				ProjectAliases: map[string]string{"prod/cert-manager": "cert-manager", "admin": "admin stuff"},
+				HideProjects:   []string{"admin"},
+			},
+			given: []timeEntry{
+				{Project: "prod/cert-manager", Description: "reviewing PR 3574"},
+				{Project: "admin", Description: "expense report"},
+				{Description: "emails"},
+			},
+			want: []timeEntry{
+				{Project: "cert-manager", Description: "reviewing PR 3574"},
+				{Description: "emails"},
+			},
+		},
+		{
+			name:    "invalid regular expression",
+			conf:    Config{Rewrite: []RewriteRule{{Match: `(`, Replace: ""}}},
+			wantErr: "rewrite rule #1: match: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:    "missing match",
+			conf:    Config{Rewrite: []RewriteRule{{Replace: "foo"}}},
+			wantErr: "rewrite rule #1: match is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw, err := newRewriter(tt.conf)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rw.apply(tt.given))
+		})
+	}
+
+	t.Run("rewritten entries get merged", func(t *testing.T) {
+		rw, err := newRewriter(Config{Rewrite: []RewriteRule{{Match: `\s*\(.*\)$`, Replace: ""}}})
+		require.NoError(t, err)
+
+		got := mergeSimilarEntries(rw.apply([]timeEntry{
+			{Project: "prod/cert-manager", Description: "standup (monday)", Duration: 15 * time.Minute},
+			{Project: "prod/cert-manager", Description: "standup (tuesday)", Duration: 15 * time.Minute},
+		}))
+		assert.Equal(t, []timeEntry{{Project: "prod/cert-manager", Description: "standup", Duration: 30 * time.Minute}}, got)
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/maelvls/clockidup/clockify"
+	"github.com/maelvls/clockidup/logutil"
+)
+
+// entryRule is the compiled version of a Rule from the config.
+type entryRule struct {
+	project     *regexp.Regexp
+	client      *regexp.Regexp
+	description *regexp.Regexp
+	tag         string
+
+	billable *bool
+	addTags  []string
+}
+
+func compileRules(rules []Rule) ([]entryRule, error) {
+	var compiled []entryRule
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.SetBillable == nil && len(rule.AddTags) == 0 {
+			return nil, fmt.Errorf("rule %s: one of set-billable or add-tags is required", name)
+		}
+
+		c := entryRule{tag: rule.Tag, billable: rule.SetBillable, addTags: rule.AddTags}
+		var err error
+		if rule.Project != "" {
+			c.project, err = regexp.Compile(rule.Project)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: project: %s", name, err)
+			}
+		}
+		if rule.Client != "" {
+			c.client, err = regexp.Compile(rule.Client)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: client: %s", name, err)
+			}
+		}
+		if rule.Description != "" {
+			c.description, err = regexp.Compile(rule.Description)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: description: %s", name, err)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches tells whether the entry meets all the conditions of the rule. A
+// rule without conditions matches every entry.
+func (r entryRule) matches(project, client, description string, tags []string) bool {
+	if r.project != nil && !r.project.MatchString(project) {
+		return false
+	}
+	if r.client != nil && !r.client.MatchString(client) {
+		return false
+	}
+	if r.description != nil && !r.description.MatchString(description) {
+		return false
+	}
+	if r.tag != "" && !hasTag(timeEntry{Tags: tags}, r.tag) {
+		return false
+	}
+	return true
+}
+
+// applyRules returns the billable flag and the tags of an entry once the
+// rules are applied in order. The added tags count for the tag condition of
+// the rules that come after.
+func applyRules(rules []entryRule, project, client, description string, billable bool, tags []string) (bool, []string) {
+	tags = append([]string(nil), tags...)
+	for _, rule := range rules {
+		if !rule.matches(project, client, description, tags) {
+			continue
+		}
+		if rule.billable != nil {
+			billable = *rule.billable
+		}
+		for _, tag := range rule.addTags {
+			if !hasTag(timeEntry{Tags: tags}, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return billable, tags
+}
+
+// overlayRules applies the rules to the entries without changing anything
+// in Clockify. What the entries earned follows their new billable flag.
+func overlayRules(entries []timeEntry, rules []entryRule) []timeEntry {
+	if len(rules) == 0 {
+		return entries
+	}
+	var result []timeEntry
+	for _, entry := range entries {
+		billable, tags := applyRules(rules, entry.Project, entry.Client, entry.Description, entry.Billable, entry.Tags)
+		switch {
+		case billable && !entry.Billable:
+			entry.Earned = earned(entry.Duration, entry.Rate)
+		case !billable:
+			entry.Earned = 0
+		}
+		entry.Billable = billable
+		if len(tags) > 0 {
+			entry.Tags = tags
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// planRules returns the updates needed for the entries to follow the rules.
+// Like with 'clockidup bulk', the locked entries are returned separately.
+// The tags given in add-tags must already exist in the workspace.
+func planRules(entries []clockify.TimeEntry, rules []entryRule, projects []clockify.Project, tags []clockify.Tag) (changes []bulkChange, locked []clockify.TimeEntry, _ error) {
+	projectMap := make(map[string]clockify.Project)
+	for _, p := range projects {
+		projectMap[p.ID] = p
+	}
+	tagNames := make(map[string]string)
+	tagIDs := make(map[string]string)
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+		tagIDs[strings.ToLower(tag.Name)] = tag.ID
+	}
+
+	for _, entry := range entries {
+		var names []string
+		for _, id := range entry.TagIds {
+			names = append(names, tagNames[id])
+		}
+		project := projectMap[entry.ProjectID]
+		billable, newNames := applyRules(rules, project.Name, project.ClientName, entry.Description, entry.Billable, names)
+
+		update := entry.UpdateRequest()
+		update.Billable = billable
+		for _, name := range newNames[len(names):] {
+			id, ok := tagIDs[strings.ToLower(name)]
+			if !ok {
+				return nil, nil, fmt.Errorf("the tag '%s' does not exist in the workspace, create it in Clockify first", name)
+			}
+			update.TagIds = append(append([]string(nil), update.TagIds...), id)
+		}
+
+		if update.Billable == entry.Billable && len(update.TagIds) == len(entry.TagIds) {
+			continue
+		}
+		if entry.IsLocked {
+			locked = append(locked, entry)
+			continue
+		}
+		changes = append(changes, bulkChange{Entry: entry, Update: update})
+	}
+	return changes, locked, nil
+}
+
+// rulesDiff returns the rows of the preview of 'clockidup apply-rules', e.g.:
+//
+//  DATE               DESCRIPTION         BILLABLE   TAGS
+//  2021-07-01 09:15   reviewing PR 3574   no → yes   + meeting
+func rulesDiff(changes []bulkChange, tags []clockify.Tag) [][]string {
+	tagNames := make(map[string]string)
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	var rows [][]string
+	for _, c := range changes {
+		billable := ""
+		if c.Entry.Billable != c.Update.Billable {
+			billable = yesNo(c.Entry.Billable) + " → " + yesNo(c.Update.Billable)
+		}
+		var added []string
+		for _, id := range c.Update.TagIds[len(c.Entry.TagIds):] {
+			added = append(added, "+ "+tagNames[id])
+		}
+		sort.Strings(added)
+		rows = append(rows, []string{c.Entry.TimeInterval.Start.Local().Format("2006-01-02 15:04"), c.Entry.Description, billable, strings.Join(added, ", ")})
+	}
+	return rows
+}
+
+func runApplyRules(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("apply-rules", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	yesFlag := flags.Bool("yes", false, "Don't ask for confirmation.")
+	concurrency := flags.Int("concurrency", defaultBulkConcurrency, "How many entries are updated at the same time.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required, e.g. 'clockidup apply-rules --from 2021-07-01 --to 2021-07-31'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", *concurrency)
+	}
+
+	rules, err := compileRules(conf.Rules)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("no rules in the config, see 'clockidup help' for an example")
+	}
+
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return err
+	}
+	entries, err := client.TimeEntries(workspace.ID, workspace.Memberships[0].UserID, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %s", err)
+	}
+	projects, err := client.Projects(workspace.ID)
+	if err != nil {
+		return fmt.Errorf("while fetching projects: %s", err)
+	}
+	tags, err := client.Tags(workspace.ID)
+	if err != nil {
+		return fmt.Errorf("while fetching tags: %s", err)
+	}
+
+	changes, locked, err := planRules(entries, rules, projects, tags)
+	if err != nil {
+		return err
+	}
+	for _, entry := range locked {
+		logutil.Infof("the time entry '%s' of %s is locked and is left as is", entry.Description, entry.TimeInterval.Start.Local().Format(layoutISO))
+	}
+	if len(changes) == 0 {
+		fmt.Println("All the time entries already follow the rules.")
+		return nil
+	}
+
+	err = printTable(os.Stdout, []string{"DATE", "DESCRIPTION", "BILLABLE", "TAGS"}, rulesDiff(changes, tags))
+	if err != nil {
+		return err
+	}
+
+	return confirmAndApply(client, workspace.ID, changes, *yesFlag, *concurrency)
+}
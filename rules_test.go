@@ -0,0 +1,145 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+func Test_compileRules(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name    string
+		given   []Rule
+		wantErr string
+	}{
+		{name: "no rules"},
+		{name: "valid rule", given: []Rule{{Client: "^ACME$", SetBillable: &yes}}},
+		{
+			name:    "rule without action",
+			given:   []Rule{{Name: "acme", Client: "^ACME$"}},
+			wantErr: "rule acme: one of set-billable or add-tags is required",
+		},
+		{
+			name:    "unnamed rule with invalid regexp",
+			given:   []Rule{{Description: "(", AddTags: []string{"meeting"}}},
+			wantErr: "rule #1: description: error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRules(tt.given)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func mustCompileRules(t *testing.T, rules ...Rule) []entryRule {
+	compiled, err := compileRules(rules)
+	require.NoError(t, err)
+	return compiled
+}
+
+func Test_applyRules(t *testing.T) {
+	yes, no := true, false
+	rules := mustCompileRules(t,
+		Rule{Client: "^ACME$", SetBillable: &yes},
+		Rule{Description: `(?i)\bmeeting\b`, SetBillable: &no, AddTags: []string{"meeting"}},
+		Rule{Tag: "meeting", Project: "^prod/", AddTags: []string{"internal"}},
+	)
+
+	tests := []struct {
+		name                         string
+		project, client, description string
+		billable                     bool
+		tags                         []string
+		wantBillable                 bool
+		wantTags                     []string
+	}{
+		{name: "no rule matches", project: "oss/cert-manager", description: "reviewing PR 3574"},
+		{name: "the client matches", project: "acme/website", client: "ACME", description: "fixing the footer", wantBillable: true},
+		{
+			name: "a later rule wins", project: "acme/website", client: "ACME", description: "weekly Meeting",
+			wantTags: []string{"meeting"},
+		},
+		{
+			name: "the added tags count for the next rules", project: "prod/cert-manager", description: "team meeting", billable: true,
+			tags:     []string{"Meeting"},
+			wantTags: []string{"Meeting", "internal"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			billable, tags := applyRules(rules, tt.project, tt.client, tt.description, tt.billable, tt.tags)
+			assert.Equal(t, tt.wantBillable, billable)
+			assert.Equal(t, tt.wantTags, tags)
+		})
+	}
+}
+
+func Test_selectBillable_withRules(t *testing.T) {
+	yes, no := true, false
+	rules := mustCompileRules(t,
+		Rule{Client: "^ACME$", SetBillable: &yes},
+		Rule{Project: "^internal$", SetBillable: &no},
+	)
+	rate := clockify.HourlyRate{Amount: 10000, Currency: "USD"}
+
+	got := selectBillable([]timeEntry{
+		{Description: "fixing the footer", Project: "acme/website", Client: "ACME", Duration: 90 * time.Minute, Rate: rate},
+		{Description: "expenses", Project: "internal", Duration: time.Hour, Billable: true, Rate: rate, Earned: 10000},
+		{Description: "reviewing PR 3574", Project: "prod/cert-manager", Duration: time.Hour, Billable: true, Rate: rate, Earned: 10000},
+	}, rules)
+
+	assert.Equal(t, []timeEntry{
+		{Description: "fixing the footer", Project: "acme/website", Client: "ACME", Duration: 90 * time.Minute, Billable: true, Rate: rate, Earned: 15000},
+		{Description: "reviewing PR 3574", Project: "prod/cert-manager", Duration: time.Hour, Billable: true, Rate: rate, Earned: 10000},
+	}, got)
+}
+
+func Test_planRules(t *testing.T) {
+	yes := true
+	projects := []clockify.Project{
+		{ID: "project-1-uid", Name: "acme/website", ClientName: "ACME"},
+		{ID: "project-2-uid", Name: "prod/cert-manager"},
+	}
+	tags := []clockify.Tag{{ID: "tag-1-uid", Name: "meeting"}, {ID: "tag-2-uid", Name: "acme"}}
+	entries := []clockify.TimeEntry{
+		{ID: "entry-1-uid", Description: "fixing the footer", ProjectID: "project-1-uid"},
+		{ID: "entry-2-uid", Description: "weekly meeting", ProjectID: "project-1-uid", Billable: true, TagIds: []string{"tag-2-uid"}},
+		{ID: "entry-3-uid", Description: "reviewing PR 3574", ProjectID: "project-2-uid"},
+		{ID: "entry-4-uid", Description: "fixing the header", ProjectID: "project-1-uid", IsLocked: true},
+	}
+
+	t.Run("only the entries that change are returned", func(t *testing.T) {
+		rules := mustCompileRules(t, Rule{Client: "^ACME$", SetBillable: &yes, AddTags: []string{"ACME"}})
+
+		changes, locked, err := planRules(entries, rules, projects, tags)
+		require.NoError(t, err)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, "entry-1-uid", changes[0].Entry.ID)
+		assert.True(t, changes[0].Update.Billable)
+		assert.Equal(t, []string{"tag-2-uid"}, changes[0].Update.TagIds)
+		require.Len(t, locked, 1)
+		assert.Equal(t, "entry-4-uid", locked[0].ID)
+
+		date := changes[0].Entry.TimeInterval.Start.Local().Format("2006-01-02 15:04")
+		assert.Equal(t, [][]string{{date, "fixing the footer", "no → yes", "+ acme"}}, rulesDiff(changes, tags))
+	})
+
+	t.Run("the tags must exist", func(t *testing.T) {
+		rules := mustCompileRules(t, Rule{Project: "cert-manager", AddTags: []string{"oss"}})
+
+		_, _, err := planRules(entries, rules, projects, tags)
+		require.EqualError(t, err, "the tag 'oss' does not exist in the workspace, create it in Clockify first")
+	})
+}
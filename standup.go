@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+const defaultBlockerTag = "blocker"
+
+// The default template for 'clockidup standup'. The Blockers section is only
+// shown when there are blockers.
+const defaultStandupTemplate = `Yesterday ({{ .Yesterday.Title }}):
+{{- range .Yesterday.Lines }}
+- {{ . }}
+{{- end }}
+
+Today:
+{{- range .Today.Lines }}
+- {{ . }}
+{{- end }}
+{{- if .Blockers }}
+
+Blockers:
+{{- range .Blockers }}
+- {{ . }}
+{{- end }}
+{{- end }}
+{{- if .Warnings }}
+{{ range .Warnings }}
+{{ . }}
+{{- end }}
+{{- end }}
+`
+
+// standup is what the standup template is given.
+type standup struct {
+	Yesterday section
+	Today     section
+	Blockers  []string
+
+	// Warnings are about the projects that are close to their estimate,
+	// see budgetWarnings.
+	Warnings []string
+}
+
+// previousWorkingDay returns the last day before 'today' that is a working
+// day. When 'target-hours' is set, a working day is a day with a non-zero
+// target; otherwise, Saturdays and Sundays are skipped.
+func previousWorkingDay(conf Config, today time.Time) (time.Time, error) {
+	day := startOfDay(today)
+	for i := 0; i < 14; i++ {
+		day = day.AddDate(0, 0, -1)
+
+		target, hasTarget, err := targetForDay(conf, day)
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch {
+		case hasTarget && target > 0:
+			return day, nil
+		case !hasTarget && day.Weekday() != time.Saturday && day.Weekday() != time.Sunday:
+			return day, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no working day found in the 14 days before %s, check 'target-hours' and 'holidays'", today.Format(layoutISO))
+}
+
+// blockersFrom returns the descriptions of the entries tagged with the
+// given tag, without duplicates.
+func blockersFrom(entries []timeEntry, tag string) []string {
+	var blockers []string
+	seen := make(map[string]bool)
+	for _, entry := range sortedByStart(entries) {
+		if !hasTag(entry, tag) || seen[entry.Description] {
+			continue
+		}
+		seen[entry.Description] = true
+		blockers = append(blockers, entry.Description)
+	}
+	return blockers
+}
+
+// appendUnique appends the items that are not already in the list.
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func hasTag(entry timeEntry, tag string) bool {
+	for _, t := range entry.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func promptBlockers() ([]string, error) {
+	var answer string
+	err := survey.AskOne(&survey.Multiline{
+		Message: "Any blockers? (one per line)",
+	}, &answer)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockers []string
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-"))
+		if line != "" {
+			blockers = append(blockers, line)
+		}
+	}
+	return blockers, nil
+}
+
+const blockersTitle = "Blockers"
+
+// reviewStandup is like reviewMessage. The blockers are shown as a third
+// section.
+func reviewStandup(client clockifyClient, workspaceName string, s standup) (standup, error) {
+	msg := message{Sections: []section{s.Yesterday, s.Today}}
+	if len(s.Blockers) > 0 {
+		msg.Sections = append(msg.Sections, section{Title: blockersTitle, Lines: s.Blockers})
+	}
+
+	edited, err := reviewMessage(client, workspaceName, msg)
+	if err != nil {
+		return standup{}, err
+	}
+
+	s.Yesterday = edited.section(s.Yesterday.Title)
+	s.Today = edited.section(s.Today.Title)
+	s.Blockers = edited.section(blockersTitle).Lines
+	return s, nil
+}
+
+func renderStandup(tmplStr string, s standup) (string, error) {
+	tmpl, err := template.New("standup").Parse(tmplStr)
+	if err != nil {
+		return "", fmt.Errorf("standup: template: %s", err)
+	}
+
+	var b strings.Builder
+	err = tmpl.Execute(&b, s)
+	if err != nil {
+		return "", fmt.Errorf("standup: template: %s", err)
+	}
+	return b.String(), nil
+}
+
+func runStandup(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("standup", flag.ContinueOnError)
+	ask := flags.Bool("blockers", false, "Prompt for blockers in addition to the entries tagged with the blocker tag.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	tmplStr := defaultStandupTemplate
+	if conf.Standup.Template != "" {
+		tmplStr = conf.Standup.Template
+	}
+	blockerTag := defaultBlockerTag
+	if conf.Standup.BlockerTag != "" {
+		blockerTag = conf.Standup.BlockerTag
+	}
+
+	rw, err := newRewriter(conf)
+	if err != nil {
+		return err
+	}
+	l, err := newLinker(conf)
+	if err != nil {
+		return err
+	}
+	format, err := linkFormat(conf.Links)
+	if err != nil {
+		return err
+	}
+
+	mode, err := mergeMode()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	yesterday, err := previousWorkingDay(conf, now)
+	if err != nil {
+		return err
+	}
+
+	var s standup
+	var all []timeEntry
+	for _, day := range []struct {
+		day     time.Time
+		section *section
+		title   string
+		ongoing bool
+	}{
+		{day: yesterday, section: &s.Yesterday, title: dayTitle(yesterday, now)},
+		{day: now, section: &s.Today, title: dayTitle(now, now), ongoing: true},
+	} {
+		entries, workspace, projects, err := fetchTimeEntries(client, time.Now, workspaceName, startOfDay(day.day), endOfDay(day.day))
+		if err != nil {
+			return fmt.Errorf("while fetching time entries: %w", err)
+		}
+		s.Warnings = appendUnique(s.Warnings, standupWarnings(conf, rw, workspace, projects, entries)...)
+
+		all = append(all, rw.apply(entries)...)
+
+		// The rules are about the Clockify names, which is why --billable
+		// is applied before the rewriting.
+		entries, err = billableOnly(conf, entries)
+		if err != nil {
+			return err
+		}
+		entries = rw.apply(entries)
+		merged, err := mergeEntries(entries, mode)
+		if err != nil {
+			return err
+		}
+		merged, err = sortEntries(merged, *sortFlag)
+		if err != nil {
+			return err
+		}
+		*day.section = newSection(day.title, merged, lineOptions{Ongoing: day.ongoing, Start: mode == mergeNone, End: *timesFlag, Money: *moneyFlag})
+	}
+
+	s.Blockers = blockersFrom(all, blockerTag)
+	if *ask {
+		prompted, err := promptBlockers()
+		if err != nil {
+			return err
+		}
+		s.Blockers = append(s.Blockers, prompted...)
+	}
+
+	if *editFlag {
+		s, err = reviewStandup(client, workspaceName, s)
+		if err != nil {
+			return err
+		}
+	}
+
+	linked := l.message(message{Sections: []section{s.Yesterday, s.Today}}, format)
+	s.Yesterday, s.Today = linked.Sections[0], linked.Sections[1]
+	s.Blockers = l.lines(s.Blockers, format)
+
+	out, err := renderStandup(tmplStr, s)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(out)
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_previousWorkingDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    Config
+		today   time.Time
+		want    time.Time
+		wantErr string
+	}{
+		{
+			name:  "tuesday gives monday",
+			today: mustParse("2021-07-06T10:00:00Z"),
+			want:  mustParse("2021-07-05T00:00:00Z"),
+		},
+		{
+			name:  "monday gives friday",
+			today: mustParse("2021-07-05T10:00:00Z"),
+			want:  mustParse("2021-07-02T00:00:00Z"),
+		},
+		{
+			name:  "holidays are skipped",
+			conf:  Config{TargetHours: map[string]float64{"monday": 7.5, "thursday": 7.5, "friday": 7.5}, Holidays: []string{"2021-07-02"}},
+			today: mustParse("2021-07-05T10:00:00Z"),
+			want:  mustParse("2021-07-01T00:00:00Z"),
+		},
+		{
+			name:  "days without target hours are skipped",
+			conf:  Config{TargetHours: map[string]float64{"monday": 7.5, "tuesday": 7.5, "wednesday": 0}},
+			today: mustParse("2021-07-08T10:00:00Z"),
+			want:  mustParse("2021-07-06T00:00:00Z"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := previousWorkingDay(tt.conf, tt.today)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_blockersFrom(t *testing.T) {
+	got := blockersFrom([]timeEntry{
+		{Description: "waiting for the DNS01 credentials", Tags: []string{"Blocker"}, Start: mustParse("2021-07-05T14:00:00Z")},
+		{Description: "reviewing PR 3574", Start: mustParse("2021-07-05T10:00:00Z")},
+		{Description: "waiting for the DNS01 credentials", Tags: []string{"blocker"}, Start: mustParse("2021-07-06T09:00:00Z")},
+		{Description: "CI is down", Tags: []string{"meeting", "blocker"}, Start: mustParse("2021-07-05T09:00:00Z")},
+	}, "blocker")
+	assert.Equal(t, []string{"CI is down", "waiting for the DNS01 credentials"}, got)
+}
+
+func Test_renderStandup(t *testing.T) {
+	s := standup{
+		Yesterday: section{Title: "Friday", Lines: []string{"[4.6] prod/cert-manager: preparing for 1.2"}},
+		Today:     section{Title: "Monday", Lines: []string{"[1.2] prod/cert-manager: triaging #2037 (ongoing)"}},
+	}
+
+	t.Run("without blockers", func(t *testing.T) {
+		got, err := renderStandup(defaultStandupTemplate, s)
+		require.NoError(t, err)
+		assert.Equal(t, heredoc.Doc(`
+			Yesterday (Friday):
+			- [4.6] prod/cert-manager: preparing for 1.2
+
+			Today:
+			- [1.2] prod/cert-manager: triaging #2037 (ongoing)
+		`), got)
+	})
+
+	t.Run("with blockers", func(t *testing.T) {
+		s := s
+		s.Blockers = []string{"waiting for the DNS01 credentials"}
+		got, err := renderStandup(defaultStandupTemplate, s)
+		require.NoError(t, err)
+		assert.Equal(t, heredoc.Doc(`
+			Yesterday (Friday):
+			- [4.6] prod/cert-manager: preparing for 1.2
+
+			Today:
+			- [1.2] prod/cert-manager: triaging #2037 (ongoing)
+
+			Blockers:
+			- waiting for the DNS01 credentials
+		`), got)
+	})
+
+	t.Run("with budget warnings", func(t *testing.T) {
+		s := s
+		s.Warnings = []string{"warning: prod/cert-manager used 85% of its estimate (34h of 40h)"}
+		got, err := renderStandup(defaultStandupTemplate, s)
+		require.NoError(t, err)
+		assert.Equal(t, heredoc.Doc(`
+			Yesterday (Friday):
+			- [4.6] prod/cert-manager: preparing for 1.2
+
+			Today:
+			- [1.2] prod/cert-manager: triaging #2037 (ongoing)
+
+			warning: prod/cert-manager used 85% of its estimate (34h of 40h)
+		`), got)
+	})
+
+	t.Run("with custom fields", func(t *testing.T) {
+		s := s
+		s.Today.Entries = []timeEntry{{CustomFields: map[string]string{"ticket": "CM-2037"}}}
+		s.Today.Lines = append(s.Today.Lines, "[.5] admin: expenses")
+		got, err := renderStandup(`{{ range $i, $line := .Today.Lines }}
+- {{ $line }}{{ with $.Today.Field $i "ticket" }} ({{ . }}){{ end }}{{ end }}`, s)
+		require.NoError(t, err)
+		assert.Equal(t, heredoc.Doc(`
+
+			- [1.2] prod/cert-manager: triaging #2037 (ongoing) (CM-2037)
+			- [.5] admin: expenses`), got)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := renderStandup("{{ .Nope }", s)
+		require.EqualError(t, err, `standup: template: template: standup:1: unexpected "}" in operand`)
+	})
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The bar of a project that takes 100% of the time is this wide.
+const statsBarWidth = 30
+
+// stats is what 'clockidup stats' shows about a range of days.
+type stats struct {
+	From, To time.Time
+
+	Total    time.Duration
+	Billable time.Duration
+
+	// Longest first. An entry with several tags counts for each of its
+	// tags; the entries with no tag are under "no-tag".
+	Projects []share
+	Tags     []share
+
+	// The days with at least one entry.
+	WorkedDays int
+
+	LongestDay      time.Time
+	LongestDayTotal time.Duration
+}
+
+type share struct {
+	Name     string
+	Duration time.Duration
+}
+
+// computeStats adds up the entries. The entries still going on end at
+// 'now'.
+func computeStats(entries []timeEntry, from, to, now time.Time) stats {
+	s := stats{From: from, To: to}
+
+	projects := make(map[string]time.Duration)
+	tags := make(map[string]time.Duration)
+	for _, entry := range entries {
+		d := endOrNow(entry, now).Sub(entry.Start)
+
+		s.Total += d
+		if entry.Billable {
+			s.Billable += d
+		}
+
+		project := entry.Project
+		if project == "" {
+			project = "no-project"
+		}
+		projects[project] += d
+
+		if len(entry.Tags) == 0 {
+			tags["no-tag"] += d
+		}
+		for _, tag := range entry.Tags {
+			tags[tag] += d
+		}
+	}
+
+	s.Projects = sortedShares(projects)
+	s.Tags = sortedShares(tags)
+
+	days := loggedPerDay(entries, from.Location(), now)
+	s.WorkedDays = len(days)
+	var longest string
+	for day, d := range days {
+		if d > s.LongestDayTotal || (d == s.LongestDayTotal && day < longest) {
+			longest, s.LongestDayTotal = day, d
+		}
+	}
+	if longest != "" {
+		s.LongestDay, _ = time.ParseInLocation(layoutISO, longest, from.Location())
+	}
+
+	return s
+}
+
+// loggedPerDay adds up the entries of each day. The keys are of the form
+// "2021-07-05". The days are the ones on which the entries started in the
+// given location. The entries still going on end at 'now'.
+func loggedPerDay(entries []timeEntry, loc *time.Location, now time.Time) map[string]time.Duration {
+	days := make(map[string]time.Duration)
+	for _, entry := range entries {
+		days[entry.Start.In(loc).Format(layoutISO)] += endOrNow(entry, now).Sub(entry.Start)
+	}
+	return days
+}
+
+// sortedShares sorts by duration, longest first, and then by name.
+func sortedShares(m map[string]time.Duration) []share {
+	var shares []share
+	for name, d := range m {
+		shares = append(shares, share{Name: name, Duration: d})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Duration != shares[j].Duration {
+			return shares[i].Duration > shares[j].Duration
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	return shares
+}
+
+func percent(part, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
+
+// renderStats prints the stats, e.g.:
+//
+//  2021-07-01..2021-07-31: 120h30 over 21 day(s), 5h44 per day on average
+//  Longest day: 2021-07-14 Wed (9h15)
+//  Billable: 98h (81%), non-billable: 22h30 (19%)
+//
+//  Projects:
+//  prod/cert-manager    80h   66% ████████████████████
+//  oss/cert-manager   20h30   17% █████
+//  admin                20h   17% █████
+//
+//  Tags:
+//  no-tag   100h30   83% █████████████████████████
+//  meeting     20h   17% █████
+//
+// The 'colorize' func is given the color of the project.
+func renderStats(w io.Writer, s stats, colors map[string]string, colorize func(text, color string) string) {
+	if s.Total == 0 {
+		fmt.Fprintf(w, "%s..%s: no time entries.\n", s.From.Format(layoutISO), s.To.Format(layoutISO))
+		return
+	}
+
+	fmt.Fprintf(w, "%s..%s: %s over %d day(s), %s per day on average\n",
+		s.From.Format(layoutISO), s.To.Format(layoutISO), formatDuration(s.Total), s.WorkedDays, formatDuration(s.Total/time.Duration(s.WorkedDays)))
+	fmt.Fprintf(w, "Longest day: %s %s (%s)\n", s.LongestDay.Format(layoutISO), s.LongestDay.Format("Mon"), formatDuration(s.LongestDayTotal))
+	fmt.Fprintf(w, "Billable: %s (%.0f%%), non-billable: %s (%.0f%%)\n",
+		formatDuration(s.Billable), percent(s.Billable, s.Total), formatDuration(s.Total-s.Billable), percent(s.Total-s.Billable, s.Total))
+
+	for _, group := range []struct {
+		title  string
+		shares []share
+		colors map[string]string
+	}{
+		{title: "Projects", shares: s.Projects, colors: colors},
+		{title: "Tags", shares: s.Tags},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", group.title)
+
+		nameWidth, durWidth := 0, 0
+		for _, sh := range group.shares {
+			if l := len([]rune(sh.Name)); l > nameWidth {
+				nameWidth = l
+			}
+			if l := len(formatDuration(sh.Duration)); l > durWidth {
+				durWidth = l
+			}
+		}
+
+		for _, sh := range group.shares {
+			p := percent(sh.Duration, s.Total)
+			bar := strings.Repeat("█", int(math.Round(p*statsBarWidth/100)))
+			if bar == "" {
+				bar = "▏"
+			}
+			fmt.Fprintf(w, "%-*s  %*s  %3.0f%% %s\n", nameWidth, sh.Name, durWidth, formatDuration(sh.Duration), p, colorize(bar, group.colors[sh.Name]))
+		}
+	}
+}
+
+func runStats(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "First day, e.g. 2021-07-01.")
+	toFlag := flags.String("to", "today", "Last day.")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *fromFlag == "" {
+		return fmt.Errorf("--from is required, e.g. 'clockidup stats --from 2021-07-01 --to 2021-07-31'")
+	}
+	from, err := parseDay(*fromFlag)
+	if err != nil {
+		return err
+	}
+	to, err := parseDay(*toFlag)
+	if err != nil {
+		return err
+	}
+	if to.Before(startOfDay(from)) {
+		return fmt.Errorf("--to (%s) is before --from (%s)", to.Format(layoutISO), from.Format(layoutISO))
+	}
+
+	entries, _, projects, err := fetchTimeEntries(client, time.Now, workspaceName, startOfDay(from), endOfDay(to))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+	entries, err = billableOnly(conf, entries)
+	if err != nil {
+		return err
+	}
+
+	renderStats(os.Stdout, computeStats(entries, startOfDay(from), startOfDay(to), time.Now()), projectColors(projects), colorizeANSI)
+	return nil
+}
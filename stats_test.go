@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stats(t *testing.T) {
+	from, to := mustParse("2021-07-05T00:00:00Z"), mustParse("2021-07-09T00:00:00Z")
+	now := mustParse("2021-07-09T12:00:00Z")
+	entries := []timeEntry{
+		{Project: "prod/cert-manager", Billable: true, Tags: []string{"review"}, Start: mustParse("2021-07-08T09:00:00Z"), End: mustParse("2021-07-08T15:00:00Z")},
+		{Project: "admin", Tags: []string{"meeting", "review"}, Start: mustParse("2021-07-06T14:00:00Z"), End: mustParse("2021-07-06T15:00:00Z")},
+		{Project: "prod/cert-manager", Billable: true, Start: mustParse("2021-07-06T09:00:00Z"), End: mustParse("2021-07-06T11:00:00Z")},
+		{Start: mustParse("2021-07-05T09:00:00Z"), End: mustParse("2021-07-05T10:00:00Z")},
+	}
+
+	got := computeStats(entries, from, to, now)
+	assert.Equal(t, stats{
+		From:            from,
+		To:              to,
+		Total:           10 * time.Hour,
+		Billable:        8 * time.Hour,
+		Projects:        []share{{"prod/cert-manager", 8 * time.Hour}, {"admin", time.Hour}, {"no-project", time.Hour}},
+		Tags:            []share{{"review", 7 * time.Hour}, {"no-tag", 3 * time.Hour}, {"meeting", time.Hour}},
+		WorkedDays:      3,
+		LongestDay:      mustParse("2021-07-08T00:00:00Z"),
+		LongestDayTotal: 6 * time.Hour,
+	}, got)
+
+	var b strings.Builder
+	renderStats(&b, got, map[string]string{"admin": "#03A9F4"}, func(text, color string) string {
+		if color == "" {
+			return text
+		}
+		return "<" + text + ">"
+	})
+	assert.Equal(t, heredoc.Doc(`
+		2021-07-05..2021-07-09: 10h over 3 day(s), 3h20 per day on average
+		Longest day: 2021-07-08 Thu (6h)
+		Billable: 8h (80%), non-billable: 2h (20%)
+
+		Projects:
+		prod/cert-manager  8h   80% ████████████████████████
+		admin              1h   10% <███>
+		no-project         1h   10% ███
+
+		Tags:
+		review   7h   70% █████████████████████
+		no-tag   3h   30% █████████
+		meeting  1h   10% ███
+	`), b.String())
+}
+
+func Test_stats_empty(t *testing.T) {
+	var b strings.Builder
+	from := mustParse("2021-07-05T00:00:00Z")
+	renderStats(&b, computeStats(nil, from, from, from), nil, func(text, _ string) string { return text })
+	assert.Equal(t, "2021-07-05..2021-07-05: no time entries.\n", b.String())
+}
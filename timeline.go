@@ -0,0 +1,256 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mgutz/ansi"
+
+	"github.com/maelvls/clockidup/clockify"
+)
+
+// Each column of the timeline is a quarter of an hour.
+const timelineSlot = 15 * time.Minute
+
+// timelineRow is one project in the timeline.
+type timelineRow struct {
+	Project string
+	Color   string // Of the form "#03A9F4". Empty means no color.
+	Total   time.Duration
+
+	// Slots[i] is how long the project was worked on during the i-th
+	// quarter of an hour.
+	Slots []time.Duration
+}
+
+// buildTimeline puts the entries in quarter-of-an-hour slots between 'from'
+// and 'to', one row per project. The rows are in the order in which the
+// projects were first worked on. The entries that are still going on end at
+// 'now'.
+func buildTimeline(entries []timeEntry, colors map[string]string, from, to, now time.Time) []timelineRow {
+	slots := int(to.Sub(from) / timelineSlot)
+
+	var rows []timelineRow
+	rowOf := make(map[string]int)
+	for _, entry := range sortedByStart(entries) {
+		project := entry.Project
+		if project == "" {
+			project = "no-project"
+		}
+		i, ok := rowOf[project]
+		if !ok {
+			i = len(rows)
+			rowOf[project] = i
+			rows = append(rows, timelineRow{Project: project, Color: colors[entry.Project], Slots: make([]time.Duration, slots)})
+		}
+
+		start, end := entry.Start, endOrNow(entry, now)
+		rows[i].Total += end.Sub(start)
+		for s := 0; s < slots; s++ {
+			slotStart := from.Add(time.Duration(s) * timelineSlot)
+			slotEnd := slotStart.Add(timelineSlot)
+			overlapStart, overlapEnd := start, end
+			if slotStart.After(overlapStart) {
+				overlapStart = slotStart
+			}
+			if slotEnd.Before(overlapEnd) {
+				overlapEnd = slotEnd
+			}
+			if overlapEnd.After(overlapStart) {
+				rows[i].Slots[s] += overlapEnd.Sub(overlapStart)
+			}
+		}
+	}
+	return rows
+}
+
+// timelineBounds returns the working hours of the day, widened to include
+// the entries that started earlier or ended later. The bounds are rounded to
+// the hour.
+func timelineBounds(entries []timeEntry, from, to, now time.Time) (time.Time, time.Time) {
+	loc := from.Location()
+	for _, entry := range entries {
+		if entry.Start.Before(from) {
+			from = entry.Start.In(loc)
+		}
+		if end := endOrNow(entry, now); end.After(to) {
+			to = end.In(loc)
+		}
+	}
+
+	// Truncate can't be used since it rounds in UTC, which is not the
+	// same as rounding in time zones such as UTC+05:30.
+	hour := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	}
+	if !to.Equal(hour(to)) {
+		to = hour(to).Add(time.Hour)
+	}
+	return hour(from), to
+}
+
+// renderTimeline prints the rows as a horizontal bar chart:
+//
+//                          09  10  11  12  13  14  15  16  17
+//  prod/cert-manager  4h30 ▕ ██████████  ▏    ████████       ▏
+//  admin                1h ▕█       ███                      ▏
+//
+// A full block means that the project was worked on for at least half of
+// the quarter of an hour; a thin bar means less than that. The 'colorize'
+// func is given the project's color.
+func renderTimeline(w io.Writer, rows []timelineRow, from time.Time, colorize func(text, color string) string) {
+	nameWidth, totalWidth := 0, 0
+	for _, row := range rows {
+		if l := len([]rune(row.Project)); l > nameWidth {
+			nameWidth = l
+		}
+		if l := len(formatDuration(row.Total)); l > totalWidth {
+			totalWidth = l
+		}
+	}
+
+	slotsPerHour := int(time.Hour / timelineSlot)
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", nameWidth+totalWidth+3))
+	if len(rows) > 0 {
+		for s := 0; s < len(rows[0].Slots); s += slotsPerHour {
+			hour := from.Add(time.Duration(s) * timelineSlot).Format("15")
+			header.WriteString(hour + strings.Repeat(" ", slotsPerHour-len(hour)))
+		}
+	}
+	fmt.Fprintln(w, strings.TrimRight(header.String(), " "))
+
+	for _, row := range rows {
+		var bar strings.Builder
+		for _, d := range row.Slots {
+			switch {
+			case d >= timelineSlot/2:
+				bar.WriteString("█")
+			case d > 0:
+				bar.WriteString("▏")
+			default:
+				bar.WriteString(" ")
+			}
+		}
+		fmt.Fprintf(w, "%-*s %*s ▕%s▏\n", nameWidth, row.Project, totalWidth, formatDuration(row.Total), colorize(bar.String(), row.Color))
+	}
+}
+
+// ansi256 returns the code of the closest color among the 256 colors of
+// xterm to the given color of the form "#03A9F4".
+func ansi256(hex string) (int, bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return 0, false
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	r, g, b := int(rgb>>16&0xff), int(rgb>>8&0xff), int(rgb&0xff)
+
+	// The 6x6x6 color cube (16 to 231) uses these levels.
+	levels := []int{0, 95, 135, 175, 215, 255}
+	closest := func(v int) int {
+		best := 0
+		for i, l := range levels {
+			if abs(v-l) < abs(v-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := closest(r), closest(g), closest(b)
+	code := 16 + 36*ri + 6*gi + bi
+	dist := sq(r-levels[ri]) + sq(g-levels[gi]) + sq(b-levels[bi])
+
+	// The grayscale ramp (232 to 255) goes from 8 to 238 by steps of 10.
+	// It is closer for the grayish colors.
+	gray := (r + g + b) / 3
+	grayIndex := (gray - 8 + 5) / 10
+	if grayIndex < 0 {
+		grayIndex = 0
+	}
+	if grayIndex > 23 {
+		grayIndex = 23
+	}
+	level := 8 + 10*grayIndex
+	if d := sq(r-level) + sq(g-level) + sq(b-level); d < dist {
+		code = 232 + grayIndex
+	}
+
+	return code, true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sq(v int) int { return v * v }
+
+// projectColors maps the project names to their color in Clockify, e.g.
+// "#03A9F4".
+func projectColors(projects []clockify.Project) map[string]string {
+	colors := make(map[string]string)
+	for _, p := range projects {
+		colors[p.Name] = p.Color
+	}
+	return colors
+}
+
+// colorizeANSI colors the text using the closest of the 256 colors.
+func colorizeANSI(text, color string) string {
+	code, ok := ansi256(color)
+	if !ok {
+		return text
+	}
+	return ansi.Color(text, strconv.Itoa(code))
+}
+
+func runTimeline(client clockifyClient, conf Config, workspaceName string, args []string) error {
+	flags := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	dayArg := "today"
+	if flags.NArg() > 0 {
+		dayArg = flags.Arg(0)
+	}
+	day, err := parseDay(dayArg)
+	if err != nil {
+		return err
+	}
+
+	from, to, err := workingHours(conf, day)
+	if err != nil {
+		return err
+	}
+
+	entries, _, projects, err := fetchTimeEntries(client, time.Now, workspaceName, startOfDay(day), endOfDay(day))
+	if err != nil {
+		return fmt.Errorf("while fetching time entries: %w", err)
+	}
+	entries, err = billableOnly(conf, entries)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No time entries.")
+		return nil
+	}
+
+	now := time.Now()
+	from, to = timelineBounds(entries, from, to, now)
+	renderTimeline(os.Stdout, buildTimeline(entries, projectColors(projects), from, to, now), from, colorizeANSI)
+	return nil
+}
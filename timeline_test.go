@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_timeline(t *testing.T) {
+	from, to := mustParse("2021-07-05T09:00:00Z"), mustParse("2021-07-05T12:00:00Z")
+	now := mustParse("2021-07-05T11:40:00Z")
+	entries := []timeEntry{
+		{Project: "prod/cert-manager", Description: "triaging", Start: mustParse("2021-07-05T11:00:00Z")},
+		{Description: "emails", Start: mustParse("2021-07-05T10:20:00Z"), End: mustParse("2021-07-05T10:25:00Z")},
+		{Project: "prod/cert-manager", Description: "reviewing PR 3574", Start: mustParse("2021-07-05T09:15:00Z"), End: mustParse("2021-07-05T10:15:00Z")},
+	}
+
+	rows := buildTimeline(entries, map[string]string{"prod/cert-manager": "#03A9F4"}, from, to, now)
+	assert.Equal(t, "prod/cert-manager", rows[0].Project)
+	assert.Equal(t, "#03A9F4", rows[0].Color)
+	assert.Equal(t, 100*time.Minute, rows[0].Total)
+	assert.Equal(t, "no-project", rows[1].Project)
+
+	var b strings.Builder
+	renderTimeline(&b, rows, from, func(text, color string) string {
+		if color == "" {
+			return text
+		}
+		return "<" + text + ">"
+	})
+	assert.Equal(t, heredoc.Doc(`
+		                        09  10  11
+		prod/cert-manager 1h40 ▕< ████   ███ >▏
+		no-project          5m ▕     ▏      ▏
+	`), b.String())
+}
+
+func Test_timelineBounds(t *testing.T) {
+	from, to := mustParse("2021-07-05T09:00:00Z"), mustParse("2021-07-05T18:00:00Z")
+
+	gotFrom, gotTo := timelineBounds([]timeEntry{
+		{Start: mustParse("2021-07-05T08:20:00Z"), End: mustParse("2021-07-05T08:40:00Z")},
+		{Start: mustParse("2021-07-05T17:50:00Z")},
+	}, from, to, mustParse("2021-07-05T19:10:00Z"))
+	assert.Equal(t, mustParse("2021-07-05T08:00:00Z"), gotFrom)
+	assert.Equal(t, mustParse("2021-07-05T20:00:00Z"), gotTo)
+
+	gotFrom, gotTo = timelineBounds(nil, from, to, mustParse("2021-07-05T19:10:00Z"))
+	assert.Equal(t, from, gotFrom)
+	assert.Equal(t, to, gotTo)
+}
+
+func Test_ansi256(t *testing.T) {
+	tests := []struct {
+		color  string
+		want   int
+		wantOK bool
+	}{
+		{color: "#000000", want: 16, wantOK: true},
+		{color: "#FFFFFF", want: 231, wantOK: true},
+		{color: "#FF0000", want: 196, wantOK: true},
+		{color: "#03A9F4", want: 39, wantOK: true},
+		{color: "#808080", want: 244, wantOK: true},
+		{color: "blue"},
+		{color: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			got, ok := ansi256(tt.color)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
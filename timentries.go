@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/maelvls/clockidup/clockify"
@@ -15,11 +18,32 @@ import (
 // their end date is empty. In that case, we use the current UTC time (all times
 // are in UTC in Clockify) to determine its duration.
 type timeEntry struct {
+	// IDs of the Clockify time entries. Once merged, an entry may have more
+	// than one ID.
+	IDs []string
+
 	Project     string
+	Client      string // The client of the project, if any.
 	Description string
 	Task        string
 	Duration    time.Duration
 	Billable    bool
+	Tags        []string
+
+	// Rate is the hourly rate of the entry, and Earned is what the entry
+	// earned in cents. Earned is zero for the non-billable entries and
+	// for the entries with no rate.
+	Rate   clockify.HourlyRate
+	Earned int64
+
+	// CustomFields maps the names of the custom fields to their value,
+	// e.g. "ticket" to "PROJ-123". See formatCustomValue.
+	CustomFields map[string]string
+
+	// Start and End are UTC. End is zero when the time entry is still going
+	// on.
+	Start time.Time
+	End   time.Time
 }
 
 // Testing purposes.
@@ -27,7 +51,19 @@ type clockifyClient interface {
 	Workspaces() ([]clockify.Workspace, error)
 	Projects(workspaceID string) ([]clockify.Project, error)
 	TimeEntries(workspaceID, userID string, start, end time.Time) ([]clockify.TimeEntry, error)
+	InProgressTimeEntry(workspaceID, userID string) (*clockify.TimeEntry, error)
 	Task(workspaceID, projectID, taskID string) (clockify.Task, error)
+	Tasks(workspaceID, projectID string) ([]clockify.Task, error)
+	Tags(workspaceID string) ([]clockify.Tag, error)
+	Clients(workspaceID string) ([]clockify.Client, error)
+	CustomFields(workspaceID string) ([]clockify.CustomField, error)
+	TimeEntry(workspaceID, timeEntryID string) (clockify.TimeEntry, error)
+	UpdateTimeEntry(workspaceID, timeEntryID string, update clockify.UpdateTimeEntryRequest) (clockify.TimeEntry, error)
+	CreateTimeEntry(workspaceID string, create clockify.CreateTimeEntryRequest) (clockify.TimeEntry, error)
+	DeleteTimeEntry(workspaceID, timeEntryID string) error
+	CreateProject(workspaceID, name string) (clockify.Project, error)
+	CreateTask(workspaceID, projectID, name string) (clockify.Task, error)
+	CreateTag(workspaceID, name string) (clockify.Tag, error)
 }
 
 // Times are UTC.
@@ -35,28 +71,48 @@ func timeEntriesForDay(client clockifyClient, now func() time.Time, workspaceNam
 	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
 	end := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, day.Location())
 
-	workspaces, err := client.Workspaces()
-	if err != nil {
-		return nil, fmt.Errorf("%s", err)
-	}
-	if len(workspaces) == 0 {
-		return nil, fmt.Errorf("no workspaces found, check your token and re-login via 'clockidup login'")
-	}
+	return timeEntriesForRange(client, now, workspaceName, start, end)
+}
 
-	workspace, workspaceFound := findWorkspace(workspaces, workspaceName)
-	if !workspaceFound {
-		return nil, fmt.Errorf("unable to find workspace '%s'. Use 'clockidup select' or pass a workspace name with '--workspace'", workspaceName)
+// timeEntriesForRange returns the time entries that started between start
+// and end. Like with timeEntriesForDay, the order is the one returned by
+// Clockify, i.e., the most recent entries come first.
+func timeEntriesForRange(client clockifyClient, now func() time.Time, workspaceName string, start, end time.Time) ([]timeEntry, error) {
+	entries, _, _, err := fetchTimeEntries(client, now, workspaceName, start, end)
+	return entries, err
+}
+
+// fetchTimeEntries is like timeEntriesForRange but also returns the
+// workspace and the projects that had to be fetched along with the entries,
+// so that they don't have to be fetched a second time.
+func fetchTimeEntries(client clockifyClient, now func() time.Time, workspaceName string, start, end time.Time) ([]timeEntry, clockify.Workspace, []clockify.Project, error) {
+	workspace, err := workspaceByName(client, workspaceName)
+	if err != nil {
+		return nil, clockify.Workspace{}, nil, err
 	}
 	userID := workspace.Memberships[0].UserID
 
 	timeEntries, err := client.TimeEntries(workspace.ID, userID, start, end)
 	if err != nil {
-		return nil, fmt.Errorf("%s", err)
+		return nil, clockify.Workspace{}, nil, fmt.Errorf("%s", err)
 	}
 
+	entries, projects, err := shortenEntries(client, now, workspace, timeEntries)
+	if err != nil {
+		return nil, clockify.Workspace{}, nil, err
+	}
+	return entries, workspace, projects, nil
+}
+
+// shortenEntries turns the time entries returned by Clockify into the
+// entries we work with, fetching the project, task, tag and custom field
+// names along the way. It also returns the projects of the workspace.
+func shortenEntries(client clockifyClient, now func() time.Time, workspace clockify.Workspace, timeEntries []clockify.TimeEntry) ([]timeEntry, []clockify.Project, error) {
+	userID := workspace.Memberships[0].UserID
+
 	projects, err := client.Projects(workspace.ID)
 	if err != nil {
-		return nil, fmt.Errorf("%s", err)
+		return nil, nil, fmt.Errorf("%s", err)
 	}
 	projectMap := make(map[string]*clockify.Project)
 	for i := range projects {
@@ -64,16 +120,56 @@ func timeEntriesForDay(client clockifyClient, now func() time.Time, workspaceNam
 		projectMap[proj.ID] = proj
 	}
 
+	// When fetching a range of days, the same task is likely to show up
+	// many times; we only want to fetch it once.
+	taskNames := make(map[string]string)
+
+	// The tags are only fetched when at least one entry has a tag.
+	var tagNames map[string]string
+	for _, entry := range timeEntries {
+		if len(entry.TagIds) == 0 {
+			continue
+		}
+		tags, err := client.Tags(workspace.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("while fetching tags: %s", err)
+		}
+		tagNames = make(map[string]string)
+		for _, tag := range tags {
+			tagNames[tag.ID] = tag.Name
+		}
+		break
+	}
+
+	// Like the tags, the custom fields are only fetched when at least one
+	// entry has a custom field.
+	var fieldNames map[string]string
+	for _, entry := range timeEntries {
+		if len(entry.CustomFieldValues) == 0 {
+			continue
+		}
+		fields, err := client.CustomFields(workspace.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("while fetching custom fields: %s", err)
+		}
+		fieldNames = make(map[string]string)
+		for _, field := range fields {
+			fieldNames[field.ID] = field.Name
+		}
+		break
+	}
+
 	// Find the corresponding task when the taskId is set.
 	var shortEntries []timeEntry
 	for _, entry := range timeEntries {
-		taskName := ""
-		if entry.TaskID != "" {
+		taskName, alreadyFetched := taskNames[entry.TaskID]
+		if entry.TaskID != "" && !alreadyFetched {
 			task, err := client.Task(entry.WorkspaceID, entry.ProjectID, entry.TaskID)
 			if err != nil {
-				return nil, fmt.Errorf("while fetching task for time entry '%s: %s': %s", projectMap[entry.ProjectID].Name, entry.Description, err)
+				return nil, nil, fmt.Errorf("while fetching task for time entry '%s: %s': %s", projectMap[entry.ProjectID].Name, entry.Description, err)
 			}
 			taskName = task.Name
+			taskNames[entry.TaskID] = taskName
 		}
 
 		// When the time entry is still "ticking" i.e., the user has not
@@ -85,32 +181,69 @@ func timeEntriesForDay(client clockifyClient, now func() time.Time, workspaceNam
 			duration = now().UTC().Sub(entry.TimeInterval.Start)
 		}
 
-		projectName := ""
+		var tags []string
+		for _, tagID := range entry.TagIds {
+			tags = append(tags, tagNames[tagID])
+		}
+
+		projectName, clientName := "", ""
+		var project *clockify.Project
 		if entry.ProjectID != "" {
 			p, ok := projectMap[entry.ProjectID]
 			if !ok {
-				return nil, fmt.Errorf("programmer mistake: projectID '%s' was supposed to exist in the projectMap!", entry.ProjectID)
+				return nil, nil, fmt.Errorf("programmer mistake: projectID '%s' was supposed to exist in the projectMap!", entry.ProjectID)
+			}
+
+			projectName, clientName = p.Name, p.ClientName
+			project = p
+		}
+
+		var customFields map[string]string
+		for _, v := range entry.CustomFieldValues {
+			if v.Value == nil {
+				continue
+			}
+			name, ok := fieldNames[v.CustomFieldID]
+			if !ok {
+				name = v.Name
 			}
+			if customFields == nil {
+				customFields = make(map[string]string)
+			}
+			customFields[name] = formatCustomValue(v.Value)
+		}
 
-			projectName = p.Name
+		rate := hourlyRate(workspace, project, userID)
+		var earnedCents int64
+		if entry.Billable {
+			earnedCents = earned(duration, rate)
 		}
 
 		shortEntries = append(shortEntries, timeEntry{
+			IDs:         []string{entry.ID},
 			Project:     projectName,
+			Client:      clientName,
 			Description: entry.Description,
 			Task:        taskName,
 			Duration:    duration,
 			Billable:    entry.Billable,
+			Tags:        tags,
+			Rate:        rate,
+			Earned:      earnedCents,
+			Start:       entry.TimeInterval.Start,
+			End:         entry.TimeInterval.End,
+
+			CustomFields: customFields,
 		})
 	}
 
-	return shortEntries, nil
+	return shortEntries, projects, nil
 }
 
 // When onlyBillable is enabled, we leave out the non-billable entries.
-func selectBillable(entries []timeEntry) []timeEntry {
+func selectBillable(entries []timeEntry, rules []entryRule) []timeEntry {
 	var selected []timeEntry
-	for _, entry := range entries {
+	for _, entry := range overlayRules(entries, rules) {
 		if entry.Billable {
 			selected = append(selected, entry)
 		}
@@ -118,6 +251,19 @@ func selectBillable(entries []timeEntry) []timeEntry {
 	return selected
 }
 
+// billableOnly leaves out the non-billable entries when --billable is set,
+// taking the billable rules of the configuration into account.
+func billableOnly(conf Config, entries []timeEntry) ([]timeEntry, error) {
+	if !*onlyBillable {
+		return entries, nil
+	}
+	rules, err := compileRules(conf.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return selectBillable(entries, rules), nil
+}
+
 // mergeSimilarEntries merges similar time entries by summing up their
 // durations. Similar entries have the same project, task and description
 // simultaneously. For example, given the following time entries:
@@ -145,7 +291,8 @@ func selectBillable(entries []timeEntry) []timeEntry {
 //   | project-1 |        | "Project meeting"          | 1h       |
 //
 // Note that the order of the merged entries corresponds to the order of first
-// appearance of the similar entries.
+// appearance of the similar entries. The start of a merged entry is the start
+// of its earliest entry.
 func mergeSimilarEntries(entries []timeEntry) []timeEntry {
 	type key struct{ project, task, descr string }
 	merged := make(map[key]timeEntry)
@@ -156,6 +303,20 @@ func mergeSimilarEntries(entries []timeEntry) []timeEntry {
 		existing, alreadySeen := merged[k]
 		if alreadySeen {
 			existing.Duration += entry.Duration
+			existing.Earned += entry.Earned
+			existing.IDs = append(existing.IDs, entry.IDs...)
+
+			// The merged entry is still going on as long as one of
+			// its entries is still going on.
+			if entry.End.IsZero() {
+				existing.End = time.Time{}
+			}
+
+			// The merged entry starts when its earliest entry
+			// started, which is what --sort chronological uses.
+			if entry.Start.Before(existing.Start) {
+				existing.Start = entry.Start
+			}
 			merged[k] = existing
 			continue
 		}
@@ -171,6 +332,227 @@ func mergeSimilarEntries(entries []timeEntry) []timeEntry {
 	return mergedEntries
 }
 
+// The ways entries can be merged with --merge.
+const (
+	mergeExact   = "exact"   // Same project, task and description.
+	mergeFuzzy   = "fuzzy"   // Same project and task, and similar descriptions.
+	mergeTask    = "task"    // Same project and task.
+	mergeProject = "project" // Same project.
+	mergeNone    = "none"    // No merging at all.
+)
+
+// Two descriptions are "similar" when the edit distance between the
+// normalized descriptions is at most 20% of the length of the longest one.
+const fuzzyThreshold = 0.2
+
+// mergeEntries merges the entries according to the --merge mode. Like with
+// mergeSimilarEntries, the merged entries are in the order of first
+// appearance.
+//
+// With "task" and "project", the description of the merged entry is the name
+// of the task (when merging by task) or the list of the descriptions, e.g.
+// "reviewing PR 3574; standup".
+func mergeEntries(entries []timeEntry, mode string) ([]timeEntry, error) {
+	switch mode {
+	case mergeExact, "":
+		return mergeSimilarEntries(entries), nil
+	case mergeNone:
+		return entries, nil
+	case mergeFuzzy:
+		return mergeGroups(groupEntries(entries, func(a, b timeEntry) bool {
+			return a.Project == b.Project && a.Task == b.Task && similar(a.Description, b.Description)
+		}), func(first timeEntry, _ []string) string {
+			return first.Description
+		}), nil
+	case mergeTask:
+		return mergeGroups(groupEntries(entries, func(a, b timeEntry) bool {
+			return a.Project == b.Project && a.Task == b.Task
+		}), func(first timeEntry, descriptions []string) string {
+			if first.Task != "" {
+				return first.Task
+			}
+			return strings.Join(descriptions, "; ")
+		}), nil
+	case mergeProject:
+		merged := mergeGroups(groupEntries(entries, func(a, b timeEntry) bool {
+			return a.Project == b.Project
+		}), func(_ timeEntry, descriptions []string) string {
+			return strings.Join(descriptions, "; ")
+		})
+		for i := range merged {
+			merged[i].Task = ""
+		}
+		return merged, nil
+	default:
+		return nil, fmt.Errorf("--merge must be one of exact, fuzzy, task, project or none, got '%s'", mode)
+	}
+}
+
+// groupEntries puts the entries that are 'same' as the first entry of a
+// group in that group.
+func groupEntries(entries []timeEntry, same func(a, b timeEntry) bool) [][]timeEntry {
+	var groups [][]timeEntry
+	for _, entry := range entries {
+		found := false
+		for i := range groups {
+			if same(groups[i][0], entry) {
+				groups[i] = append(groups[i], entry)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []timeEntry{entry})
+		}
+	}
+	return groups
+}
+
+// mergeGroups sums up the durations of each group. The merged entry is the
+// first entry of the group with the description returned by 'describe',
+// which is given the unique descriptions of the group.
+func mergeGroups(groups [][]timeEntry, describe func(first timeEntry, descriptions []string) string) []timeEntry {
+	var merged []timeEntry
+	for _, group := range groups {
+		m := group[0]
+		m.IDs = nil
+		m.Duration = 0
+		m.Earned = 0
+
+		var descriptions []string
+		seen := make(map[string]bool)
+		for _, entry := range group {
+			m.Duration += entry.Duration
+			m.Earned += entry.Earned
+			m.IDs = append(m.IDs, entry.IDs...)
+			if entry.End.IsZero() {
+				m.End = time.Time{}
+			}
+			if entry.Start.Before(m.Start) {
+				m.Start = entry.Start
+			}
+			if entry.Description != "" && !seen[entry.Description] {
+				seen[entry.Description] = true
+				descriptions = append(descriptions, entry.Description)
+			}
+		}
+		m.Description = describe(group[0], descriptions)
+
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+var (
+	nonAlphanumeric = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+	numbers         = regexp.MustCompile(`\d+`)
+)
+
+// normalize lowercases the description and removes the punctuation, e.g.
+// "Review: PR #3574!" becomes "review pr 3574".
+func normalize(descr string) string {
+	return strings.TrimSpace(nonAlphanumeric.ReplaceAllString(strings.ToLower(descr), " "))
+}
+
+// similar tells whether two descriptions are close enough to be merged with
+// --merge fuzzy. The numbers must be the same so that "PR 3574" and "PR 3575"
+// are not merged.
+func similar(a, b string) bool {
+	a, b = normalize(a), normalize(b)
+	if a == b {
+		return true
+	}
+	if strings.Join(numbers.FindAllString(a, -1), " ") != strings.Join(numbers.FindAllString(b, -1), " ") {
+		return false
+	}
+
+	longest := len([]rune(a))
+	if l := len([]rune(b)); l > longest {
+		longest = l
+	}
+	return float64(levenshtein(a, b)) <= fuzzyThreshold*float64(longest)
+}
+
+// levenshtein returns the minimum number of single-character insertions,
+// deletions and substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// The orders that the standup lines can be sorted in with --sort.
+const (
+	sortChronological = "chronological" // Earliest start first.
+	sortReverse       = "reverse"       // Latest start first.
+	sortDuration      = "duration"      // Longest first.
+	sortProject       = "project"       // By project name, then chronological.
+	sortAlpha         = "alpha"         // By description.
+)
+
+// sortEntries returns the merged entries in the order in which they are
+// shown in the standup. When no order is given, the entries are shown in the
+// reverse order of the one returned by Clockify, which is the order
+// clockidup has always used: since Clockify returns the most recent entries
+// first, a merged entry shows up where its most recent entry was.
+func sortEntries(entries []timeEntry, order string) ([]timeEntry, error) {
+	sorted := make([]timeEntry, 0, len(entries))
+	for i := range entries {
+		sorted = append(sorted, entries[len(entries)-i-1])
+	}
+
+	var less func(a, b timeEntry) bool
+	switch order {
+	case "":
+		return sorted, nil
+	case sortChronological:
+		less = func(a, b timeEntry) bool { return a.Start.Before(b.Start) }
+	case sortReverse:
+		less = func(a, b timeEntry) bool { return a.Start.After(b.Start) }
+	case sortDuration:
+		less = func(a, b timeEntry) bool { return a.Duration > b.Duration }
+	case sortProject:
+		less = func(a, b timeEntry) bool {
+			if a.Project != b.Project {
+				return strings.ToLower(a.Project) < strings.ToLower(b.Project)
+			}
+			return a.Start.Before(b.Start)
+		}
+	case sortAlpha:
+		less = func(a, b timeEntry) bool { return strings.ToLower(a.Description) < strings.ToLower(b.Description) }
+	default:
+		return nil, fmt.Errorf("--sort must be one of chronological, reverse, duration, project or alpha, got '%s'", order)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
+
 // Now, we want to mash the project name and task name into the description
 // of the time entry. The format is "project 1: task 1: work on clockidup".
 func String(entry timeEntry) string {
@@ -185,6 +567,25 @@ func String(entry timeEntry) string {
 	return str
 }
 
+// workspaceByName fetches the workspaces and returns the one with the given
+// name.
+func workspaceByName(client clockifyClient, name string) (clockify.Workspace, error) {
+	workspaces, err := client.Workspaces()
+	if err != nil {
+		return clockify.Workspace{}, fmt.Errorf("%s", err)
+	}
+	if len(workspaces) == 0 {
+		return clockify.Workspace{}, fmt.Errorf("no workspaces found, check your token and re-login via 'clockidup login'")
+	}
+
+	workspace, workspaceFound := findWorkspace(workspaces, name)
+	if !workspaceFound {
+		return clockify.Workspace{}, fmt.Errorf("unable to find workspace '%s'. Use 'clockidup select' or pass a workspace name with '--workspace'", name)
+	}
+
+	return workspace, nil
+}
+
 func findWorkspace(workspaces []clockify.Workspace, name string) (clockify.Workspace, bool) {
 	// If no workspace is selected or name provided, we return that it is not
 	// found You must now select a workspace during login or via the select
@@ -77,6 +77,31 @@ func workspaceWith(name, id, uid string) clockify.Workspace {
 	}
 }
 
+// 'clockidup 2021-07-05' used to show the entries of the UTC day, e.g. from
+// 09:00 to 08:59 the next day in Tokyo. It now shows the entries of the local
+// day, like 'clockidup today' does.
+func Test_timeEntriesForDay_isoDay(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	local := time.Local
+	time.Local = tokyo
+	defer func() { time.Local = local }()
+
+	day, err := parseDay("2021-07-05")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 7, 5, 0, 0, 0, 0, tokyo), day)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mocks.NewMockclockifyClient(ctrl)
+	client.EXPECT().Workspaces().Return([]clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}, nil)
+	client.EXPECT().TimeEntries("workspace-1-uid", "user-1-uid", time.Date(2021, 7, 5, 0, 0, 0, 0, tokyo), time.Date(2021, 7, 5, 23, 59, 59, 0, tokyo)).Return(nil, nil)
+	client.EXPECT().Projects("workspace-1-uid").Return(nil, nil)
+
+	_, err = timeEntriesForDay(client, time.Now, "workspace-1", day)
+	require.NoError(t, err)
+}
+
 func Test_timeEntriesForDay(t *testing.T) {
 	nowFixed := mustParse("2021-07-03T14:00:00Z")
 	tests := []struct {
@@ -121,9 +146,9 @@ func Test_timeEntriesForDay(t *testing.T) {
 				m.Task("workspace-1-uid", "project-1-uid", "task-1-uid").Return(clockify.Task{ID: "task-1-uid", Name: "task-1"}, nil)
 			},
 			want: []timeEntry{
-				{Project: "", Description: "work with no project", Duration: 30 * time.Minute, Billable: false},
-				{Project: "project-1", Description: "some work with project but no task", Duration: 30 * time.Minute, Billable: true},
-				{Project: "project-1", Description: "unit-test of clockidup, work with project and task", Task: "task-1", Duration: 30 * time.Minute, Billable: true},
+				{IDs: []string{"entry-1-uid"}, Project: "", Description: "work with no project", Duration: 30 * time.Minute, Billable: false, Start: mustParse("2021-07-03T13:30:00Z"), End: mustParse("2021-07-03T14:00:00Z")},
+				{IDs: []string{"entry-2-uid"}, Project: "project-1", Description: "some work with project but no task", Duration: 30 * time.Minute, Billable: true, Start: mustParse("2021-07-03T13:00:00Z"), End: mustParse("2021-07-03T13:30:00Z")},
+				{IDs: []string{"entry-3-uid"}, Project: "project-1", Description: "unit-test of clockidup, work with project and task", Task: "task-1", Duration: 30 * time.Minute, Billable: true, Start: mustParse("2021-07-03T12:30:00Z"), End: mustParse("2021-07-03T13:00:00Z")},
 			},
 		},
 		{
@@ -140,7 +165,57 @@ func Test_timeEntriesForDay(t *testing.T) {
 				m.Projects("workspace-1-uid").Return(nil, nil)
 			},
 			want: []timeEntry{
-				{Project: "", Description: "time entry that is still going on (no end time)", Duration: 30 * time.Minute},
+				{IDs: []string{"entry-1-uid"}, Project: "", Description: "time entry that is still going on (no end time)", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T13:30:00Z")},
+			},
+		},
+		{
+			name:          "tag names are resolved",
+			workspaceName: "workspace-1",
+			day:           mustParse("2021-07-03T00:00:00Z"),
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return([]clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}, nil)
+				m.TimeEntries("workspace-1-uid", "user-1-uid", mustParse("2021-07-03T00:00:00Z"), mustParse("2021-07-03T23:59:59Z")).Return([]clockify.TimeEntry{{
+					ID: "entry-1-uid", WorkspaceID: "workspace-1-uid", UserID: "user-1-uid",
+					Description:  "waiting for the DNS01 credentials",
+					TagIds:       []string{"tag-1-uid"},
+					TimeInterval: clockify.TimeInterval{Start: mustParse("2021-07-03T13:00:00Z"), End: mustParse("2021-07-03T13:30:00Z"), Duration: "PT30M"},
+				}}, nil)
+				m.Projects("workspace-1-uid").Return(nil, nil)
+				m.Tags("workspace-1-uid").Return([]clockify.Tag{{ID: "tag-1-uid", Name: "blocker"}, {ID: "tag-2-uid", Name: "meeting"}}, nil)
+			},
+			want: []timeEntry{
+				{IDs: []string{"entry-1-uid"}, Project: "", Description: "waiting for the DNS01 credentials", Duration: 30 * time.Minute, Tags: []string{"blocker"}, Start: mustParse("2021-07-03T13:00:00Z"), End: mustParse("2021-07-03T13:30:00Z")},
+			},
+		},
+		{
+			name:          "custom field names are resolved",
+			workspaceName: "workspace-1",
+			day:           mustParse("2021-07-03T00:00:00Z"),
+			givenMock: func(m *mocks.MockclockifyClientMockRecorder) {
+				m.Workspaces().Return([]clockify.Workspace{{ID: "workspace-1-uid", Name: "workspace-1", Memberships: []clockify.Memberships{{UserID: "user-1-uid"}}}}, nil)
+				m.TimeEntries("workspace-1-uid", "user-1-uid", mustParse("2021-07-03T00:00:00Z"), mustParse("2021-07-03T23:59:59Z")).Return([]clockify.TimeEntry{{
+					ID: "entry-1-uid", WorkspaceID: "workspace-1-uid", UserID: "user-1-uid",
+					Description: "reviewing PR 3574",
+					CustomFieldValues: []clockify.CustomFieldValue{
+						{CustomFieldID: "field-1-uid", Value: "CM-42"},
+						{CustomFieldID: "field-2-uid", Value: 3.5},
+						{CustomFieldID: "field-3-uid", Value: []interface{}{"review", "testing"}},
+						{CustomFieldID: "field-4-uid", Value: nil},
+						{CustomFieldID: "field-5-uid", Name: "deleted", Value: true},
+					},
+					TimeInterval: clockify.TimeInterval{Start: mustParse("2021-07-03T13:00:00Z"), End: mustParse("2021-07-03T13:30:00Z"), Duration: "PT30M"},
+				}}, nil)
+				m.Projects("workspace-1-uid").Return(nil, nil)
+				m.CustomFields("workspace-1-uid").Return([]clockify.CustomField{
+					{ID: "field-1-uid", Name: "ticket"},
+					{ID: "field-2-uid", Name: "points"},
+					{ID: "field-3-uid", Name: "phase"},
+					{ID: "field-4-uid", Name: "empty"},
+				}, nil)
+			},
+			want: []timeEntry{
+				{IDs: []string{"entry-1-uid"}, Project: "", Description: "reviewing PR 3574", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T13:00:00Z"), End: mustParse("2021-07-03T13:30:00Z"),
+					CustomFields: map[string]string{"ticket": "CM-42", "points": "3.5", "phase": "review, testing", "deleted": "true"}},
 			},
 		},
 	}
@@ -191,7 +266,7 @@ func Test_selectBillable(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := selectBillable(tt.given)
+			got := selectBillable(tt.given, nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -258,6 +333,18 @@ func Test_mergeSimilarEntries(t *testing.T) {
 				{Description: "time entry 2", Duration: 130 * time.Minute},
 			},
 		},
+		{
+			name: "the merged entry starts with its earliest entry",
+			given: []timeEntry{
+				{Description: "time entry 1", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T13:00:00Z")},
+				{Description: "time entry 2", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T12:00:00Z")},
+				{Description: "time entry 1", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T11:00:00Z")},
+			},
+			want: []timeEntry{
+				{Description: "time entry 1", Duration: 60 * time.Minute, Start: mustParse("2021-07-03T11:00:00Z")},
+				{Description: "time entry 2", Duration: 30 * time.Minute, Start: mustParse("2021-07-03T12:00:00Z")},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -266,3 +353,159 @@ func Test_mergeSimilarEntries(t *testing.T) {
 		})
 	}
 }
+
+func Test_mergeEntries(t *testing.T) {
+	given := []timeEntry{
+		{IDs: []string{"1"}, Description: "review PR 3574", Project: "cert-manager", Duration: 30 * time.Minute},
+		{IDs: []string{"2"}, Description: "standup", Project: "cert-manager", Task: "meetings", Duration: 15 * time.Minute},
+		{IDs: []string{"3"}, Description: "Reviewing PR #3574", Project: "cert-manager", Duration: 40 * time.Minute},
+		{IDs: []string{"4"}, Description: "reviewing PR 3575", Project: "cert-manager", Duration: 20 * time.Minute},
+		{IDs: []string{"5"}, Description: "emails", Duration: 10 * time.Minute},
+	}
+
+	tests := []struct {
+		name    string
+		mode    string
+		given   []timeEntry
+		want    []timeEntry
+		wantErr string
+	}{
+		{
+			name:  "exact",
+			mode:  mergeExact,
+			given: given,
+			want:  given,
+		},
+		{
+			name:  "fuzzy merges near-duplicates but not different PRs",
+			mode:  mergeFuzzy,
+			given: given,
+			want: []timeEntry{
+				{IDs: []string{"1", "3"}, Description: "review PR 3574", Project: "cert-manager", Duration: 70 * time.Minute},
+				{IDs: []string{"2"}, Description: "standup", Project: "cert-manager", Task: "meetings", Duration: 15 * time.Minute},
+				{IDs: []string{"4"}, Description: "reviewing PR 3575", Project: "cert-manager", Duration: 20 * time.Minute},
+				{IDs: []string{"5"}, Description: "emails", Duration: 10 * time.Minute},
+			},
+		},
+		{
+			name:  "task uses the task name when there is one",
+			mode:  mergeTask,
+			given: given,
+			want: []timeEntry{
+				{IDs: []string{"1", "3", "4"}, Description: "review PR 3574; Reviewing PR #3574; reviewing PR 3575", Project: "cert-manager", Duration: 90 * time.Minute},
+				{IDs: []string{"2"}, Description: "meetings", Project: "cert-manager", Task: "meetings", Duration: 15 * time.Minute},
+				{IDs: []string{"5"}, Description: "emails", Duration: 10 * time.Minute},
+			},
+		},
+		{
+			name: "project",
+			mode: mergeProject,
+			given: []timeEntry{
+				{IDs: []string{"1"}, Description: "standup", Project: "cert-manager", Task: "meetings", Duration: 15 * time.Minute},
+				{IDs: []string{"2"}, Description: "emails", Duration: 10 * time.Minute},
+				{IDs: []string{"3"}, Description: "review PR 3574", Project: "cert-manager", Duration: 30 * time.Minute},
+				{IDs: []string{"4"}, Description: "standup", Project: "cert-manager", Task: "meetings", Duration: 15 * time.Minute},
+			},
+			want: []timeEntry{
+				{IDs: []string{"1", "3", "4"}, Description: "standup; review PR 3574", Project: "cert-manager", Duration: 60 * time.Minute},
+				{IDs: []string{"2"}, Description: "emails", Duration: 10 * time.Minute},
+			},
+		},
+		{
+			name: "a merged entry is ongoing when one of its entries is",
+			mode: mergeFuzzy,
+			given: []timeEntry{
+				{Description: "triaging", Duration: 10 * time.Minute, Start: mustParse("2021-07-03T13:30:00Z")},
+				{Description: "Triaging.", Duration: 10 * time.Minute, Start: mustParse("2021-07-03T12:00:00Z"), End: mustParse("2021-07-03T12:10:00Z")},
+			},
+			want: []timeEntry{
+				{Description: "triaging", Duration: 20 * time.Minute, Start: mustParse("2021-07-03T12:00:00Z")},
+			},
+		},
+		{
+			name:  "none",
+			mode:  mergeNone,
+			given: given,
+			want:  given,
+		},
+		{
+			name:    "unknown mode",
+			mode:    "client",
+			given:   given,
+			wantErr: "--merge must be one of exact, fuzzy, task, project or none, got 'client'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeEntries(tt.given, tt.mode)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_similar(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"review PR 3574", "reviewing PR 3574", true},
+		{"Standup!", "standup", true},
+		{"PR 3574", "PR 3575", false},
+		{"standup", "stand-down", false},
+		{"emails", "email", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, similar(tt.a, tt.b))
+		})
+	}
+}
+
+func Test_levenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("", ""))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 1, levenshtein("café", "cafe"))
+	assert.Equal(t, 5, levenshtein("", "hello"))
+}
+
+func Test_sortEntries(t *testing.T) {
+	// In the order returned by Clockify (most recent first), once merged.
+	// The "standup" entry has been merged with an earlier entry at 08:00.
+	var (
+		review  = timeEntry{Project: "prod/cert-manager", Description: "reviewing PR 3574", Duration: 90 * time.Minute, Start: mustParse("2021-07-05T10:00:00Z")}
+		emails  = timeEntry{Description: "emails", Duration: 20 * time.Minute, Start: mustParse("2021-07-05T09:30:00Z")}
+		standup = timeEntry{Project: "admin", Description: "Standup", Duration: 30 * time.Minute, Start: mustParse("2021-07-05T08:00:00Z")}
+	)
+	given := []timeEntry{review, emails, standup}
+
+	tests := []struct {
+		order   string
+		want    []timeEntry
+		wantErr string
+	}{
+		{order: "", want: []timeEntry{standup, emails, review}},
+		{order: sortChronological, want: []timeEntry{standup, emails, review}},
+		{order: sortReverse, want: []timeEntry{review, emails, standup}},
+		{order: sortDuration, want: []timeEntry{review, standup, emails}},
+		{order: sortProject, want: []timeEntry{emails, standup, review}},
+		{order: sortAlpha, want: []timeEntry{emails, review, standup}},
+		{order: "random", wantErr: "--sort must be one of chronological, reverse, duration, project or alpha, got 'random'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			got, err := sortEntries(given, tt.order)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}